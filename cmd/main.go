@@ -1,18 +1,24 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
 	deps "github.com/AjStraight619/discord-bot/deps"
+	"github.com/AjStraight619/discord-bot/internal/audio"
 	"github.com/AjStraight619/discord-bot/internal/bot"
 	"github.com/AjStraight619/discord-bot/internal/config"
 	"github.com/AjStraight619/discord-bot/internal/messaging"
+	"github.com/AjStraight619/discord-bot/internal/speech"
+	"github.com/AjStraight619/discord-bot/internal/storage"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -27,6 +33,22 @@ func main() {
 
 	config.LoadConfig()
 
+	store, err := storage.Open(config.AppConfig.DBPath)
+	if err != nil {
+		log.Fatalf("Error opening storage: %v", err)
+	}
+	defer store.Close()
+
+	audioCache, err := audio.NewCache(config.AppConfig.AudioCacheDir, config.AppConfig.AudioCacheMB<<20)
+	if err != nil {
+		log.Fatalf("Error opening audio cache: %v", err)
+	}
+
+	recognizer := newRecognizer(config.AppConfig)
+	if closer, ok := recognizer.(io.Closer); ok {
+		defer closer.Close()
+	}
+
 	dg, err := discordgo.New("Bot " + config.AppConfig.DiscordKey)
 
 	if err != nil {
@@ -34,16 +56,28 @@ func main() {
 	}
 
 	dg.StateEnabled = true
-	dg.Identify.Intents = discordgo.IntentsGuilds | discordgo.IntentsGuildMessages | discordgo.IntentsGuildMembers
+	dg.Identify.Intents = discordgo.IntentsGuilds | discordgo.IntentsGuildMessages | discordgo.IntentsGuildMembers | discordgo.IntentsGuildVoiceStates
 
 	botController := &bot.BotController{
 		Session:         dg,
+		Sessions:        bot.NewSessionManager(),
+		Permissions:     bot.NewPermissionRules(store),
+		Store:           store,
+		AudioCache:      audioCache,
+		Downloads:       audio.NewDownloader(2, audio.CachedDownload(filepath.Join(binDir, "yt-dlp.exe"), audioCache)),
+		MusicLibrary:    config.AppConfig.MusicLibraryDir,
+		MaxFileBytes:    config.AppConfig.MaxAudioFileMB << 20,
+		Speech:          recognizer,
+		RecordingsDir:   config.AppConfig.RecordingsDir,
+		RecordingsURL:   config.AppConfig.RecordingsURL,
 		TimeoutDuration: time.Duration(20) * time.Minute,
 	}
 
 	botController.InitCommands()
 
 	dg.AddHandler(botController.MessageHandler)
+	dg.AddHandler(botController.InteractionHandler)
+	dg.AddHandler(botController.VoiceStateUpdateHandler)
 
 	// Open a connection to Discord
 	err = dg.Open()
@@ -53,11 +87,17 @@ func main() {
 
 	time.Sleep(3 * time.Second)
 
+	if err := botController.RegisterSlashCommands(); err != nil {
+		log.Printf("Error registering slash commands: %v", err)
+	}
+
 	fmt.Println("Bot is now running! Press CTRL+C to exit.")
 
-	cm := messaging.InitCron(dg, 15)
+	cm := messaging.InitCron(dg, 15, store)
+
+	cm.StartJobs()
 
-	cm.StartCydCron()
+	botController.RestoreQueues()
 
 	// guild := utils.FindGuildByName(dg, "King's Landing")
 
@@ -85,5 +125,26 @@ func main() {
 	<-stop
 
 	// Cleanup
+	botController.SaveQueues()
 	dg.Close()
 }
+
+// newRecognizer sets up the speech engine !transcribe uses, or returns nil when
+// none is configured.
+func newRecognizer(cfg *config.Config) speech.Recognizer {
+	switch cfg.SpeechEngine {
+	case "google":
+		google, err := speech.NewGoogle(context.Background(), cfg.SpeechLanguage)
+		if err != nil {
+			log.Printf("Error setting up Google speech, transcription is disabled: %v", err)
+			return nil
+		}
+		return google
+	case "command":
+		fields := strings.Fields(cfg.SpeechCommand)
+		return speech.Command{Path: fields[0], Args: fields[1:]}
+	case "fake":
+		return speech.Fake{Text: "testing, one two three"}
+	}
+	return nil
+}
@@ -9,22 +9,38 @@ import (
 	"github.com/sashabaranov/go-openai"
 )
 
+// ChatMessage is a previous turn of a conversation sent along with a prompt.
+type ChatMessage struct {
+	Role    string // "user" or "assistant".
+	Content string
+}
+
 // GetAIResponse calls the OpenAI API using the global configuration and returns the response.
 func GetAIResponse(prompt string) (string, error) {
+	return GetAIChatResponse(nil, prompt)
+}
+
+// GetAIChatResponse is like GetAIResponse but includes earlier turns of the
+// conversation so follow-up questions have context.
+func GetAIChatResponse(history []ChatMessage, prompt string) (string, error) {
 	client := openai.NewClient(config.AppConfig.OpenAIKey)
 	var resp openai.ChatCompletionResponse
 	var err error
 	maxRetries := 3
 	waitTime := 2 * time.Second
 
+	messages := make([]openai.ChatCompletionMessage, 0, len(history)+1)
+	for _, msg := range history {
+		messages = append(messages, openai.ChatCompletionMessage{Role: msg.Role, Content: msg.Content})
+	}
+	messages = append(messages, openai.ChatCompletionMessage{Role: "user", Content: prompt})
+
 	for i := 0; i < maxRetries; i++ {
 		resp, err = client.CreateChatCompletion(
 			context.Background(),
 			openai.ChatCompletionRequest{
-				Model: openai.GPT3Dot5Turbo,
-				Messages: []openai.ChatCompletionMessage{
-					{Role: "user", Content: prompt},
-				},
+				Model:    openai.GPT3Dot5Turbo,
+				Messages: messages,
 			},
 		)
 		if err == nil {
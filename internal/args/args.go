@@ -0,0 +1,358 @@
+// Package args declares the arguments bot commands accept and parses message
+// text into typed values according to those declarations.
+package args
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind identifies the type of value a command argument accepts.
+type Kind int
+
+const (
+	String   Kind = iota // A single word, or a "quoted string".
+	Int                  // A whole number, optionally bounded by Min/Max.
+	Duration             // 1h30m, 1:30, 90 (in Unit).
+	URL                  // An http(s) link.
+	User                 // A user mention or ID.
+	Channel              // A channel mention or ID.
+	Enum                 // One of Choices, case-insensitive.
+	Text                 // Every remaining word joined with spaces.
+	Role                 // A role mention or ID.
+)
+
+// String returns a user-facing name for the kind, used in help pages.
+func (k Kind) String() string {
+	switch k {
+	case Int:
+		return "number"
+	case Duration:
+		return "duration"
+	case URL:
+		return "link"
+	case User:
+		return "user"
+	case Channel:
+		return "channel"
+	case Enum:
+		return "choice"
+	case Role:
+		return "role"
+	default:
+		return "text"
+	}
+}
+
+// Spec describes a single argument accepted by a command.
+type Spec struct {
+	Name        string
+	Description string
+	Kind        Kind
+	Required    bool
+	Variadic    bool          // Consumes every remaining word. Only valid on the last argument.
+	Choices     []string      // Allowed values for Enum.
+	Min, Max    int           // Inclusive bounds for Int, ignored when both are zero.
+	Unit        time.Duration // Unit of a bare number for Duration, defaults to seconds.
+}
+
+// Error is returned when a command's arguments don't match its specs.
+type Error struct {
+	Arg string // Name of the offending argument, empty for general errors.
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return e.Msg
+}
+
+// Invocation is a single command found in a message along with its words.
+type Invocation struct {
+	Name  string
+	Words []string
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+// Commands splits a message into command invocations. Every unquoted word that
+// starts with prefix begins a new command, and the words up to the next command
+// belong to it. Words before the first command are ignored.
+func Commands(content, prefix string) ([]Invocation, error) {
+	tokens, err := tokenize(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var invocations []Invocation
+	for _, tok := range tokens {
+		if !tok.quoted && strings.HasPrefix(tok.text, prefix) {
+			invocations = append(invocations, Invocation{Name: strings.ToLower(tok.text)})
+			continue
+		}
+		if len(invocations) == 0 {
+			continue
+		}
+		last := &invocations[len(invocations)-1]
+		last.Words = append(last.Words, tok.text)
+	}
+	return invocations, nil
+}
+
+// Split breaks text into words, keeping "quoted strings" together.
+func Split(content string) ([]string, error) {
+	tokens, err := tokenize(content)
+	if err != nil {
+		return nil, err
+	}
+	words := make([]string, len(tokens))
+	for i, tok := range tokens {
+		words[i] = tok.text
+	}
+	return words, nil
+}
+
+func tokenize(content string) ([]token, error) {
+	var tokens []token
+	var current strings.Builder
+	inWord, inQuote := false, false
+
+	for _, r := range content {
+		switch {
+		case r == '"':
+			if inQuote {
+				tokens = append(tokens, token{text: current.String(), quoted: true})
+				current.Reset()
+				inQuote, inWord = false, false
+			} else if !inWord {
+				inQuote = true
+			} else {
+				current.WriteRune(r)
+			}
+		case inQuote:
+			current.WriteRune(r)
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				tokens = append(tokens, token{text: current.String()})
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if inQuote {
+		return nil, &Error{Msg: "unterminated quote"}
+	}
+	if inWord {
+		tokens = append(tokens, token{text: current.String()})
+	}
+	return tokens, nil
+}
+
+// Parse binds words to specs in order and converts them to typed values.
+func Parse(specs []Spec, words []string) (Values, error) {
+	raw := make(map[string][]string, len(specs))
+	i := 0
+	for _, spec := range specs {
+		if i >= len(words) {
+			break
+		}
+		if spec.Variadic || spec.Kind == Text {
+			raw[spec.Name] = words[i:]
+			i = len(words)
+			break
+		}
+		raw[spec.Name] = words[i : i+1]
+		i++
+	}
+	if i < len(words) {
+		return nil, &Error{Msg: fmt.Sprintf("unexpected argument %q", words[i])}
+	}
+	return ParseNamed(specs, raw)
+}
+
+// ParseNamed converts words that have already been matched to arguments by name,
+// such as slash command options.
+func ParseNamed(specs []Spec, raw map[string][]string) (Values, error) {
+	values := make(Values, len(specs))
+	for _, spec := range specs {
+		words := raw[spec.Name]
+		if len(words) == 0 {
+			if spec.Required {
+				return nil, &Error{Arg: spec.Name, Msg: fmt.Sprintf("missing %s", spec.Name)}
+			}
+			continue
+		}
+
+		if spec.Kind == Text {
+			values[spec.Name] = []any{strings.Join(words, " ")}
+			continue
+		}
+
+		for _, word := range words {
+			v, err := convert(spec, word)
+			if err != nil {
+				return nil, err
+			}
+			values[spec.Name] = append(values[spec.Name], v)
+		}
+	}
+	return values, nil
+}
+
+var (
+	userMention    = regexp.MustCompile(`^<@!?(\d+)>$`)
+	channelMention = regexp.MustCompile(`^<#(\d+)>$`)
+	roleMention    = regexp.MustCompile(`^<@&(\d+)>$`)
+	snowflake      = regexp.MustCompile(`^\d{15,21}$`)
+)
+
+// Mention identifies a user, channel or role mention and returns its kind and ID.
+func Mention(word string) (Kind, string, bool) {
+	if m := userMention.FindStringSubmatch(word); m != nil {
+		return User, m[1], true
+	}
+	if m := channelMention.FindStringSubmatch(word); m != nil {
+		return Channel, m[1], true
+	}
+	if m := roleMention.FindStringSubmatch(word); m != nil {
+		return Role, m[1], true
+	}
+	return 0, "", false
+}
+
+func convert(spec Spec, word string) (any, error) {
+	invalid := func(format string, a ...any) error {
+		return &Error{Arg: spec.Name, Msg: fmt.Sprintf("%s "+format, append([]any{spec.Name}, a...)...)}
+	}
+
+	switch spec.Kind {
+	case Int:
+		n, err := strconv.Atoi(word)
+		if err != nil {
+			return nil, invalid("must be a whole number, got %q", word)
+		}
+		if (spec.Min != 0 || spec.Max != 0) && (n < spec.Min || n > spec.Max) {
+			return nil, invalid("must be between %d and %d", spec.Min, spec.Max)
+		}
+		return n, nil
+
+	case Duration:
+		d, err := ParseDuration(word, spec.Unit)
+		if err != nil {
+			return nil, invalid("must be a duration like 90, 1:30 or 1h30m, got %q", word)
+		}
+		return d, nil
+
+	case URL:
+		u, err := url.ParseRequestURI(word)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, invalid("must be an http(s) link, got %q", word)
+		}
+		return word, nil
+
+	case User:
+		if m := userMention.FindStringSubmatch(word); m != nil {
+			return m[1], nil
+		}
+		if snowflake.MatchString(word) {
+			return word, nil
+		}
+		return nil, invalid("must be a user mention, got %q", word)
+
+	case Channel:
+		if m := channelMention.FindStringSubmatch(word); m != nil {
+			return m[1], nil
+		}
+		if snowflake.MatchString(word) {
+			return word, nil
+		}
+		return nil, invalid("must be a channel mention, got %q", word)
+
+	case Role:
+		if m := roleMention.FindStringSubmatch(word); m != nil {
+			return m[1], nil
+		}
+		if snowflake.MatchString(word) {
+			return word, nil
+		}
+		return nil, invalid("must be a role mention, got %q", word)
+
+	case Enum:
+		for _, choice := range spec.Choices {
+			if strings.EqualFold(choice, word) {
+				return choice, nil
+			}
+		}
+		return nil, invalid("must be one of %s", strings.Join(spec.Choices, ", "))
+
+	default:
+		return word, nil
+	}
+}
+
+// ParseDuration accepts Go durations (1h30m), clock times (1:30, 1:02:03) and
+// bare numbers, which are read in unit (seconds when unit is zero).
+func ParseDuration(s string, unit time.Duration) (time.Duration, error) {
+	if unit == 0 {
+		unit = time.Second
+	}
+
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 0 {
+			return 0, fmt.Errorf("negative duration %q", s)
+		}
+		return time.Duration(n) * unit, nil
+	}
+
+	if strings.Contains(s, ":") {
+		parts := strings.Split(s, ":")
+		if len(parts) > 3 {
+			return 0, fmt.Errorf("invalid clock time %q", s)
+		}
+		var total time.Duration
+		for _, part := range parts {
+			n, err := strconv.Atoi(part)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid clock time %q", s)
+			}
+			total = total*60 + time.Duration(n)*time.Second
+		}
+		return total, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
+
+// Usage renders the usage line for a command, e.g. "!sports <team> <player> [season]".
+func Usage(name string, specs []Spec) string {
+	parts := []string{name}
+	for _, spec := range specs {
+		label := spec.Name
+		if spec.Kind == Enum {
+			label = strings.Join(spec.Choices, "|")
+		}
+		if spec.Variadic || spec.Kind == Text {
+			label += "..."
+		}
+		if spec.Required {
+			parts = append(parts, "<"+label+">")
+		} else {
+			parts = append(parts, "["+label+"]")
+		}
+	}
+	return strings.Join(parts, " ")
+}
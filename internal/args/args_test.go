@@ -0,0 +1,198 @@
+package args
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		content string
+		want    []Invocation
+	}{
+		{
+			content: "!play url1 !news us",
+			want: []Invocation{
+				{Name: "!play", Words: []string{"url1"}},
+				{Name: "!news", Words: []string{"us"}},
+			},
+		},
+		{
+			content: `hello !sports Lakers "LeBron James" 2024`,
+			want: []Invocation{
+				{Name: "!sports", Words: []string{"Lakers", "LeBron James", "2024"}},
+			},
+		},
+		{
+			content: `!ai "what does !play do?"`,
+			want: []Invocation{
+				{Name: "!ai", Words: []string{"what does !play do?"}},
+			},
+		},
+		{
+			content: "!LEAVE",
+			want:    []Invocation{{Name: "!leave"}},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := Commands(tt.content, "!")
+		if err != nil {
+			t.Fatalf("Commands(%q) returned error: %v", tt.content, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Commands(%q) = %+v, want %+v", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestCommandsUnterminatedQuote(t *testing.T) {
+	_, err := Commands(`!ai "oops`, "!")
+	var argErr *Error
+	if !errors.As(err, &argErr) {
+		t.Fatalf("expected *Error, got %v", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	specs := []Spec{
+		{Name: "count", Kind: Int, Required: true, Min: 1, Max: 10},
+		{Name: "after", Kind: Duration, Unit: time.Minute},
+		{Name: "mode", Kind: Enum, Choices: []string{"track", "queue", "off"}},
+		{Name: "who", Kind: User},
+		{Name: "links", Kind: URL, Variadic: true},
+	}
+
+	values, err := Parse(specs, []string{"3", "1:30", "QUEUE", "<@!123456789012345678>", "https://a.example/x", "http://b.example"})
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	if got := values.Int("count", 0); got != 3 {
+		t.Errorf("count = %d, want 3", got)
+	}
+	if got := values.Duration("after", 0); got != 90*time.Second {
+		t.Errorf("after = %v, want 1m30s", got)
+	}
+	if got := values.String("mode"); got != "queue" {
+		t.Errorf("mode = %q, want queue", got)
+	}
+	if got := values.String("who"); got != "123456789012345678" {
+		t.Errorf("who = %q, want user ID", got)
+	}
+	if got := values.Strings("links"); len(got) != 2 {
+		t.Errorf("links = %v, want 2 links", got)
+	}
+}
+
+func TestParseText(t *testing.T) {
+	specs := []Spec{{Name: "question", Kind: Text, Required: true}}
+
+	values, err := Parse(specs, []string{"how", "does", "this", "work?"})
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if got := values.String("question"); got != "how does this work?" {
+		t.Errorf("question = %q", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	specs := []Spec{
+		{Name: "minutes", Kind: Int, Required: true, Min: 1, Max: 60},
+		{Name: "link", Kind: URL},
+	}
+
+	tests := []struct {
+		words []string
+		arg   string
+	}{
+		{words: nil, arg: "minutes"},
+		{words: []string{"abc"}, arg: "minutes"},
+		{words: []string{"120"}, arg: "minutes"},
+		{words: []string{"5", "not-a-link"}, arg: "link"},
+		{words: []string{"5", "https://a.example", "extra"}, arg: ""},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(specs, tt.words)
+		var argErr *Error
+		if !errors.As(err, &argErr) {
+			t.Errorf("Parse(%v) error = %v, want *Error", tt.words, err)
+			continue
+		}
+		if argErr.Arg != tt.arg {
+			t.Errorf("Parse(%v) blamed %q, want %q", tt.words, argErr.Arg, tt.arg)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		unit time.Duration
+		want time.Duration
+	}{
+		{"45", 0, 45 * time.Second},
+		{"30", time.Minute, 30 * time.Minute},
+		{"2:05", 0, 125 * time.Second},
+		{"1:00:01", 0, time.Hour + time.Second},
+		{"1h30m", 0, 90 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.in, tt.unit)
+		if err != nil {
+			t.Errorf("ParseDuration(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"-5", "1:xx", "soon"} {
+		if _, err := ParseDuration(bad, 0); err == nil {
+			t.Errorf("ParseDuration(%q) expected error", bad)
+		}
+	}
+}
+
+func TestUsage(t *testing.T) {
+	specs := []Spec{
+		{Name: "team", Required: true},
+		{Name: "player", Required: true},
+		{Name: "season"},
+		{Name: "mode", Kind: Enum, Choices: []string{"a", "b"}},
+		{Name: "links", Variadic: true},
+	}
+
+	want := "!sports <team> <player> [season] [a|b] [links...]"
+	if got := Usage("!sports", specs); got != want {
+		t.Errorf("Usage = %q, want %q", got, want)
+	}
+}
+
+func TestMention(t *testing.T) {
+	tests := []struct {
+		word string
+		kind Kind
+		id   string
+		ok   bool
+	}{
+		{"<@123>", User, "123", true},
+		{"<@!123>", User, "123", true},
+		{"<#456>", Channel, "456", true},
+		{"<@&789>", Role, "789", true},
+		{"@everyone", 0, "", false},
+	}
+
+	for _, tt := range tests {
+		kind, id, ok := Mention(tt.word)
+		if kind != tt.kind || id != tt.id || ok != tt.ok {
+			t.Errorf("Mention(%q) = %v, %q, %v; want %v, %q, %v", tt.word, kind, id, ok, tt.kind, tt.id, tt.ok)
+		}
+	}
+}
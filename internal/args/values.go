@@ -0,0 +1,51 @@
+package args
+
+import "time"
+
+// Values holds parsed arguments keyed by spec name. Variadic arguments keep
+// every value, everything else holds exactly one.
+type Values map[string][]any
+
+// Has reports whether the argument was provided.
+func (v Values) Has(name string) bool {
+	return len(v[name]) > 0
+}
+
+// String returns a String, Text, URL, User, Channel or Enum argument, or "" when
+// it was not provided.
+func (v Values) String(name string) string {
+	if !v.Has(name) {
+		return ""
+	}
+	s, _ := v[name][0].(string)
+	return s
+}
+
+// Strings returns every value of a variadic string-like argument.
+func (v Values) Strings(name string) []string {
+	out := make([]string, 0, len(v[name]))
+	for _, val := range v[name] {
+		if s, ok := val.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Int returns an Int argument, or def when it was not provided.
+func (v Values) Int(name string, def int) int {
+	if !v.Has(name) {
+		return def
+	}
+	n, _ := v[name][0].(int)
+	return n
+}
+
+// Duration returns a Duration argument, or def when it was not provided.
+func (v Values) Duration(name string, def time.Duration) time.Duration {
+	if !v.Has(name) {
+		return def
+	}
+	d, _ := v[name][0].(time.Duration)
+	return d
+}
@@ -0,0 +1,214 @@
+package audio
+
+import (
+	"container/list"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache keeps downloaded songs on disk, keyed by video ID, and evicts the least
+// recently played ones once the total size goes over a limit.
+type Cache struct {
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	size    int64
+	order   *list.List               // Most recently used at the front.
+	entries map[string]*list.Element // Key -> element holding a *cacheEntry.
+}
+
+// workDirName is the subdirectory of the cache holding in-progress downloads.
+const workDirName = ".work"
+
+type cacheEntry struct {
+	key  string
+	path string
+	size int64
+}
+
+// NewCache opens the cache in dir, picking up files left by earlier runs in
+// order of their modification times.
+func NewCache(dir string, maxBytes int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	// Work directories left behind by a crash are never finished.
+	os.RemoveAll(filepath.Join(dir, workDirName))
+
+	c := &Cache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	type existing struct {
+		entry   *cacheEntry
+		modTime time.Time
+	}
+	var found []existing
+	for _, file := range files {
+		info, err := file.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		key := strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))
+		found = append(found, existing{
+			entry:   &cacheEntry{key: key, path: filepath.Join(dir, file.Name()), size: info.Size()},
+			modTime: info.ModTime(),
+		})
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].modTime.After(found[j].modTime) })
+	for _, f := range found {
+		if _, dup := c.entries[f.entry.key]; dup {
+			continue
+		}
+		c.entries[f.entry.key] = c.order.PushBack(f.entry)
+		c.size += f.entry.size
+	}
+
+	c.mu.Lock()
+	c.evict("")
+	c.mu.Unlock()
+	return c, nil
+}
+
+// Get returns the cached file for key and marks it as recently used.
+func (c *Cache) Get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	entry := el.Value.(*cacheEntry)
+	if _, err := os.Stat(entry.path); err != nil {
+		c.remove(el)
+		return "", false
+	}
+	c.order.MoveToFront(el)
+	now := time.Now()
+	os.Chtimes(entry.path, now, now)
+	return entry.path, true
+}
+
+// Put moves the file at path into the cache under key, keeping its extension,
+// and returns its new location.
+func (c *Cache) Put(key, path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	dest := filepath.Join(c.dir, key+filepath.Ext(path))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		old := el.Value.(*cacheEntry)
+		c.remove(el)
+		if old.path != dest {
+			os.Remove(old.path)
+		}
+	}
+	if err := os.Rename(path, dest); err != nil {
+		return "", fmt.Errorf("moving %s into the cache: %w", path, err)
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, path: dest, size: info.Size()})
+	c.size += info.Size()
+	c.evict(key)
+	return dest, nil
+}
+
+// WorkDir creates an empty directory for a single download of key. It lives
+// inside the cache directory so finished files can be moved in with a rename.
+// Callers remove it when they're done.
+func (c *Cache) WorkDir(key string) (string, error) {
+	parent := filepath.Join(c.dir, workDirName)
+	if err := os.MkdirAll(parent, os.ModePerm); err != nil {
+		return "", err
+	}
+	return os.MkdirTemp(parent, key+"-*")
+}
+
+// Size returns the total size of the cached files in bytes.
+func (c *Cache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// evict deletes least recently used files until the cache fits its limit. The
+// entry for keep is never evicted, nor are files that can't be deleted right
+// now (e.g. because they're being played on Windows).
+func (c *Cache) evict(keep string) {
+	for el := c.order.Back(); el != nil && c.size > c.maxBytes; {
+		prev := el.Prev()
+		entry := el.Value.(*cacheEntry)
+		if entry.key != keep {
+			if err := os.Remove(entry.path); err == nil || os.IsNotExist(err) {
+				log.Println("🗑 Evicted from audio cache:", entry.path)
+				c.remove(el)
+			} else {
+				log.Println("⚠ Couldn't evict from audio cache:", err)
+			}
+		}
+		el = prev
+	}
+}
+
+func (c *Cache) remove(el *list.Element) {
+	entry := el.Value.(*cacheEntry)
+	c.order.Remove(el)
+	delete(c.entries, entry.key)
+	c.size -= entry.size
+}
+
+var videoIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+
+// CacheKey returns the YouTube video ID for a URL, or a hash of the URL for
+// links that don't carry one, so each song maps to a stable file name.
+func CacheKey(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+		host = strings.TrimPrefix(host, "m.")
+		host = strings.TrimPrefix(host, "music.")
+
+		var id string
+		switch host {
+		case "youtu.be":
+			id = strings.Trim(u.Path, "/")
+		case "youtube.com":
+			if v := u.Query().Get("v"); v != "" {
+				id = v
+			} else if rest, ok := strings.CutPrefix(u.Path, "/shorts/"); ok {
+				id = rest
+			} else if rest, ok := strings.CutPrefix(u.Path, "/embed/"); ok {
+				id = rest
+			}
+		}
+		if videoIDPattern.MatchString(id) {
+			return id
+		}
+	}
+
+	sum := sha1.Sum([]byte(rawURL))
+	return "url-" + hex.EncodeToString(sum[:8])
+}
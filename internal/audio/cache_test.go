@@ -0,0 +1,82 @@
+package audio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// putFile writes size bytes into a work directory and moves them into the cache.
+func putFile(t *testing.T, c *Cache, key string, size int) string {
+	t.Helper()
+	dir, err := c.WorkDir(key)
+	if err != nil {
+		t.Fatalf("WorkDir returned error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, key+".webm")
+	if err := os.WriteFile(src, make([]byte, size), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path, err := c.Put(key, src)
+	if err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	return path
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewCache(dir, 250)
+	if err != nil {
+		t.Fatalf("NewCache returned error: %v", err)
+	}
+
+	a := putFile(t, c, "aaaaaaaaaaa", 100)
+	putFile(t, c, "bbbbbbbbbbb", 100)
+	if got, ok := c.Get("aaaaaaaaaaa"); !ok || got != a {
+		t.Fatalf("Get = %q, %v; want %q", got, ok, a)
+	}
+
+	// "b" is now the least recently used, so it goes to make room for "c".
+	putFile(t, c, "ccccccccccc", 100)
+	if _, ok := c.Get("bbbbbbbbbbb"); ok {
+		t.Error("least recently used entry was not evicted")
+	}
+	if _, ok := c.Get("aaaaaaaaaaa"); !ok {
+		t.Error("recently used entry was evicted")
+	}
+	if c.Size() != 200 {
+		t.Errorf("Size = %d, want 200", c.Size())
+	}
+
+	// A new cache over the same directory picks the files back up.
+	reopened, err := NewCache(dir, 250)
+	if err != nil {
+		t.Fatalf("NewCache returned error: %v", err)
+	}
+	if _, ok := reopened.Get("ccccccccccc"); !ok {
+		t.Error("reopened cache lost an entry")
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	tests := map[string]string{
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ":            "dQw4w9WgXcQ",
+		"https://youtube.com/watch?v=dQw4w9WgXcQ&list=PL123&t=1": "dQw4w9WgXcQ",
+		"https://youtu.be/dQw4w9WgXcQ":                           "dQw4w9WgXcQ",
+		"https://music.youtube.com/watch?v=dQw4w9WgXcQ":          "dQw4w9WgXcQ",
+		"https://www.youtube.com/shorts/dQw4w9WgXcQ":             "dQw4w9WgXcQ",
+	}
+	for url, want := range tests {
+		if got := CacheKey(url); got != want {
+			t.Errorf("CacheKey(%q) = %q, want %q", url, got, want)
+		}
+	}
+
+	other := CacheKey("https://soundcloud.com/artist/track")
+	if other != CacheKey("https://soundcloud.com/artist/track") || other == CacheKey("https://soundcloud.com/artist/other") {
+		t.Errorf("CacheKey for non-YouTube links is not stable and unique: %q", other)
+	}
+}
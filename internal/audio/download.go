@@ -0,0 +1,182 @@
+package audio
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Download fetches a song's best audio format with yt-dlp into the cache and
+// returns the cached file. Songs already in the cache skip yt-dlp entirely.
+// progress, if not nil, is called with the percentage downloaded so far.
+func Download(ctx context.Context, ytdlpPath, url string, cache *Cache, progress func(percent float64)) (string, error) {
+	key := CacheKey(url)
+	if path, ok := cache.Get(key); ok {
+		log.Println("📦 Using cached audio:", path)
+		return path, nil
+	}
+
+	// Each download gets its own directory, so the only file in it once
+	// yt-dlp exits is the one it just wrote.
+	workDir, err := cache.WorkDir(key)
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(workDir)
+
+	log.Println("📥 Downloading YouTube audio with yt-dlp...")
+	output := filepath.Join(workDir, key+".%(ext)s")
+	cmd := exec.CommandContext(ctx, ytdlpPath, "-f", "bestaudio", "--no-playlist", "--newline", "-o", output, url)
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("starting yt-dlp: %w", err)
+	}
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		if percent, ok := parseProgress(scanner.Text()); ok && progress != nil {
+			progress(percent)
+		}
+	}
+	if err := cmd.Wait(); err != nil {
+		return "", fmt.Errorf("downloading with yt-dlp: %w", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(workDir, key+".*"))
+	if err != nil {
+		return "", err
+	}
+	var downloaded string
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".part") && !strings.HasSuffix(file, ".ytdl") {
+			downloaded = file
+			break
+		}
+	}
+	if downloaded == "" {
+		return "", fmt.Errorf("yt-dlp finished without writing a file")
+	}
+
+	path, err := cache.Put(key, downloaded)
+	if err != nil {
+		return "", err
+	}
+	log.Println("✅ Download complete:", path)
+	return path, nil
+}
+
+// progressLine matches yt-dlp's "[download]  42.3% of 3.21MiB at ..." lines.
+var progressLine = regexp.MustCompile(`^\[download\]\s+([0-9.]+)%`)
+
+// parseProgress extracts the percentage from a line of yt-dlp output.
+func parseProgress(line string) (float64, bool) {
+	m := progressLine.FindStringSubmatch(line)
+	if m == nil {
+		return 0, false
+	}
+	percent, err := strconv.ParseFloat(m[1], 64)
+	return percent, err == nil
+}
+
+// Cached wraps a Source so every song streamed to the end is also saved to the
+// cache, letting replays skip the source.
+type Cached struct {
+	Source Source
+	Cache  *Cache
+	FFmpeg string // Path to ffmpeg for seeking Source, found on the PATH when empty.
+}
+
+func (c Cached) Open(ctx context.Context, url string) (io.ReadCloser, error) {
+	r, err := c.Source.Open(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	key := CacheKey(url)
+	workDir, err := c.Cache.WorkDir(key)
+	if err != nil {
+		log.Println("⚠ Not caching stream:", err)
+		return r, nil
+	}
+	f, err := os.Create(filepath.Join(workDir, key+".audio"))
+	if err != nil {
+		os.RemoveAll(workDir)
+		log.Println("⚠ Not caching stream:", err)
+		return r, nil
+	}
+	return &teeReader{src: r, file: f, workDir: workDir, key: key, cache: c.Cache}, nil
+}
+
+// OpenAt streams from start without caching, as the stream isn't the whole
+// song. Sources that can't seek are streamed from the beginning, and cached,
+// while ffmpeg skips to start.
+func (c Cached) OpenAt(ctx context.Context, url string, start time.Duration) (io.ReadCloser, error) {
+	if s, ok := c.Source.(SeekingSource); ok {
+		return s.OpenAt(ctx, url, start)
+	}
+	r, err := c.Open(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return seek(ctx, c.FFmpeg, r, "pipe:0", start)
+}
+
+// teeReader copies a stream into a file and hands the file to the cache on
+// Close, but only if the stream was read to the end.
+type teeReader struct {
+	mu       sync.Mutex
+	src      io.ReadCloser
+	file     *os.File
+	workDir  string
+	key      string
+	cache    *Cache
+	complete bool
+	failed   bool
+}
+
+func (t *teeReader) Read(p []byte) (int, error) {
+	n, err := t.src.Read(p)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if n > 0 && !t.failed {
+		if _, werr := t.file.Write(p[:n]); werr != nil {
+			log.Println("⚠ Not caching stream:", werr)
+			t.failed = true
+		}
+	}
+	if err == io.EOF {
+		t.complete = true
+	}
+	return n, err
+}
+
+func (t *teeReader) Close() error {
+	err := t.src.Close()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.file.Close()
+	if t.complete && !t.failed && err == nil {
+		if path, putErr := t.cache.Put(t.key, t.file.Name()); putErr != nil {
+			log.Println("⚠ Couldn't cache stream:", putErr)
+		} else {
+			log.Println("📦 Cached streamed audio:", path)
+		}
+	}
+	os.RemoveAll(t.workDir)
+	return err
+}
@@ -0,0 +1,204 @@
+package audio
+
+import (
+	"context"
+	"math"
+	"sync"
+)
+
+// FetchFunc downloads a song and returns the path of the file, reporting the
+// percentage done through progress.
+type FetchFunc func(ctx context.Context, url string, progress func(percent float64)) (string, error)
+
+// CachedDownload returns a FetchFunc that downloads with yt-dlp into cache.
+func CachedDownload(ytdlpPath string, cache *Cache) FetchFunc {
+	return func(ctx context.Context, url string, progress func(percent float64)) (string, error) {
+		return Download(ctx, ytdlpPath, url, cache, progress)
+	}
+}
+
+// Downloader runs downloads on a fixed number of workers. Waiting jobs are
+// started in priority order, lowest first, so the song that plays next can
+// jump ahead of songs further down the queue.
+type Downloader struct {
+	fetch FetchFunc
+
+	mu      sync.Mutex
+	wake    *sync.Cond
+	waiting []*Job          // Jobs not yet picked up by a worker.
+	jobs    map[string]*Job // Unfinished jobs by URL.
+	seq     int
+}
+
+// Job is a single download. Done is closed once it has finished, failed or
+// been cancelled.
+type Job struct {
+	URL string
+
+	done     chan struct{}
+	ctx      context.Context
+	cancel   context.CancelFunc
+	priority int
+	seq      int              // Request order, breaks priority ties.
+	waiters  map[any]struct{} // Who still wants the file, guarded by the Downloader's mu.
+
+	mu       sync.Mutex
+	percent  float64
+	path     string
+	err      error
+	finished bool
+}
+
+// NewDownloader starts workers goroutines that run downloads with fetch.
+func NewDownloader(workers int, fetch FetchFunc) *Downloader {
+	d := &Downloader{
+		fetch: fetch,
+		jobs:  make(map[string]*Job),
+	}
+	d.wake = sync.NewCond(&d.mu)
+	for i := 0; i < workers; i++ {
+		go d.work()
+	}
+	return d
+}
+
+// Request asks for url to be downloaded on behalf of waiter and returns its
+// job. Requesting a URL that's already waiting or downloading returns the same
+// job, moved up to the new priority if that's sooner, and adds waiter to the
+// ones keeping it going. A waiter that requests the same URL again counts once.
+func (d *Downloader) Request(url string, priority int, waiter any) *Job {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if job, ok := d.jobs[url]; ok {
+		job.priority = min(job.priority, priority)
+		job.waiters[waiter] = struct{}{}
+		return job
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.seq++
+	job := &Job{
+		URL:      url,
+		done:     make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+		priority: priority,
+		seq:      d.seq,
+		waiters:  map[any]struct{}{waiter: {}},
+	}
+	d.jobs[url] = job
+	d.waiting = append(d.waiting, job)
+	d.wake.Signal()
+	return job
+}
+
+// Lookup returns the unfinished job for url, if any.
+func (d *Downloader) Lookup(url string) (*Job, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	job, ok := d.jobs[url]
+	return job, ok
+}
+
+// Cancel withdraws waiter's request for url. The download is stopped, whether
+// it's waiting or running, once nobody else is waiting for it.
+func (d *Downloader) Cancel(url string, waiter any) {
+	d.mu.Lock()
+	job, ok := d.jobs[url]
+	if ok {
+		delete(job.waiters, waiter)
+	}
+	if !ok || len(job.waiters) > 0 {
+		d.mu.Unlock()
+		return
+	}
+	delete(d.jobs, url)
+	waiting := false
+	for i, w := range d.waiting {
+		if w == job {
+			d.waiting = append(d.waiting[:i], d.waiting[i+1:]...)
+			waiting = true
+			break
+		}
+	}
+	d.mu.Unlock()
+
+	job.cancel()
+	if waiting {
+		job.finish("", context.Canceled)
+	}
+}
+
+func (d *Downloader) work() {
+	for {
+		d.mu.Lock()
+		for len(d.waiting) == 0 {
+			d.wake.Wait()
+		}
+		next := 0
+		for i, job := range d.waiting {
+			best := d.waiting[next]
+			if job.priority < best.priority || (job.priority == best.priority && job.seq < best.seq) {
+				next = i
+			}
+		}
+		job := d.waiting[next]
+		d.waiting = append(d.waiting[:next], d.waiting[next+1:]...)
+		d.mu.Unlock()
+
+		path, err := d.fetch(job.ctx, job.URL, job.setProgress)
+		if job.ctx.Err() != nil {
+			err = context.Canceled
+		}
+
+		d.mu.Lock()
+		if d.jobs[job.URL] == job {
+			delete(d.jobs, job.URL)
+		}
+		d.mu.Unlock()
+
+		job.cancel()
+		job.finish(path, err)
+	}
+}
+
+// Done is closed when the job has finished.
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// Result returns the downloaded file or the error that stopped the download.
+// It's only meaningful once Done is closed.
+func (j *Job) Result() (string, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.path, j.err
+}
+
+// Progress returns how much of the file has been downloaded, in percent.
+func (j *Job) Progress() float64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.percent
+}
+
+func (j *Job) setProgress(percent float64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.percent = math.Max(j.percent, percent)
+}
+
+func (j *Job) finish(path string, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.finished {
+		return
+	}
+	j.finished = true
+	j.path, j.err = path, err
+	if err == nil {
+		j.percent = 100
+	}
+	close(j.done)
+}
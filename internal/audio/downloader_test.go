@@ -0,0 +1,110 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDownloaderPriority(t *testing.T) {
+	started := make(chan string, 10)
+	release := make(chan struct{})
+	d := NewDownloader(1, func(ctx context.Context, url string, progress func(float64)) (string, error) {
+		started <- url
+		progress(50)
+		select {
+		case <-release:
+			return "/audio/" + url, nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	})
+
+	first := d.Request("first", 5, "a")
+	if got := <-started; got != "first" {
+		t.Fatalf("started %q, want first", got)
+	}
+
+	later := d.Request("later", 5, "a")
+	d.Request("next", 0, "a")
+	removed := d.Request("removed", 1, "a")
+	if again := d.Request("later", 3, "b"); again != later {
+		t.Error("requesting a waiting URL again returned a new job")
+	}
+
+	d.Cancel("removed", "a")
+	select {
+	case <-removed.Done():
+	case <-time.After(time.Second):
+		t.Fatal("cancelled job never finished")
+	}
+	if _, err := removed.Result(); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled job error = %v, want context.Canceled", err)
+	}
+
+	close(release)
+	for _, want := range []string{"next", "later"} {
+		select {
+		case got := <-started:
+			if got != want {
+				t.Errorf("started %q, want %q", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %q", want)
+		}
+	}
+
+	<-first.Done()
+	path, err := first.Result()
+	if err != nil || path != "/audio/first" {
+		t.Errorf("Result = %q, %v; want /audio/first", path, err)
+	}
+	if first.Progress() != 100 {
+		t.Errorf("Progress = %v, want 100", first.Progress())
+	}
+}
+
+func TestDownloaderCancelWaitsForLastWaiter(t *testing.T) {
+	started := make(chan string, 1)
+	d := NewDownloader(1, func(ctx context.Context, url string, progress func(float64)) (string, error) {
+		started <- url
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+
+	// Two guilds queue the same song, one of them twice.
+	job := d.Request("song", 1, "guild A")
+	d.Request("song", 1, "guild B first")
+	d.Request("song", 1, "guild B second")
+	d.Request("song", 0, "guild A") // Prefetching again doesn't count twice.
+	<-started
+
+	for _, waiter := range []any{"guild A", "guild B first", "nobody"} {
+		d.Cancel("song", waiter)
+		select {
+		case <-job.Done():
+			t.Fatalf("download stopped after %q let go while others still wait", waiter)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	d.Cancel("song", "guild B second")
+	select {
+	case <-job.Done():
+	case <-time.After(time.Second):
+		t.Fatal("download kept going after the last waiter let go")
+	}
+	if _, err := job.Result(); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled job error = %v, want context.Canceled", err)
+	}
+}
+
+func TestParseProgress(t *testing.T) {
+	if p, ok := parseProgress("[download]  42.3% of    3.21MiB at  1.02MiB/s ETA 00:02"); !ok || p != 42.3 {
+		t.Errorf("parseProgress = %v, %v; want 42.3", p, ok)
+	}
+	if _, ok := parseProgress("[youtube] dQw4w9WgXcQ: Downloading webpage"); ok {
+		t.Error("parseProgress matched a non-progress line")
+	}
+}
@@ -0,0 +1,53 @@
+package audio
+
+import (
+	"fmt"
+	"strings"
+)
+
+// nightcoreRate is how much faster (and higher) the nightcore filter plays.
+const nightcoreRate = 1.25
+
+// Filters are optional ffmpeg effects applied while encoding.
+type Filters struct {
+	BassBoost bool
+	Nightcore bool    // Speeds songs up and raises their pitch.
+	Normalize bool    // Evens out loudness with ffmpeg's loudnorm.
+	Speed     float64 // Tempo without changing pitch, 0 or 1 for normal.
+}
+
+// Rate returns how many seconds of the song play per second of output.
+func (f Filters) Rate() float64 {
+	rate := 1.0
+	if f.Nightcore {
+		rate *= nightcoreRate
+	}
+	if f.Speed > 0 {
+		rate *= f.Speed
+	}
+	return rate
+}
+
+// chain returns the filters and volume, a percentage, as an ffmpeg -af
+// argument, empty when none are on.
+func (f Filters) chain(volume int) string {
+	var chain []string
+	if f.Nightcore {
+		// Resample first so the pitch shift is the same whatever the source rate.
+		chain = append(chain, "aresample=48000", fmt.Sprintf("asetrate=%d", int(48000*nightcoreRate)), "aresample=48000")
+	}
+	if f.Speed > 0 && f.Speed != 1 {
+		chain = append(chain, fmt.Sprintf("atempo=%g", f.Speed))
+	}
+	if f.BassBoost {
+		chain = append(chain, "bass=g=10")
+	}
+	if f.Normalize {
+		chain = append(chain, "loudnorm=I=-16:TP=-1.5:LRA=11")
+	}
+	// Last, so it scales normalized audio too.
+	if volume != 100 {
+		chain = append(chain, fmt.Sprintf("volume=%g", float64(volume)/100))
+	}
+	return strings.Join(chain, ",")
+}
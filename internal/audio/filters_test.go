@@ -0,0 +1,25 @@
+package audio
+
+import "testing"
+
+func TestFilters(t *testing.T) {
+	tests := []struct {
+		filters Filters
+		chain   string
+		rate    float64
+	}{
+		{Filters{}, "", 1},
+		{Filters{Speed: 1}, "", 1},
+		{Filters{BassBoost: true, Normalize: true}, "bass=g=10,loudnorm=I=-16:TP=-1.5:LRA=11", 1},
+		{Filters{Speed: 1.5}, "atempo=1.5", 1.5},
+		{Filters{Nightcore: true, Speed: 0.8}, "aresample=48000,asetrate=60000,aresample=48000,atempo=0.8", 1},
+	}
+	for _, tt := range tests {
+		if got := tt.filters.chain(100); got != tt.chain {
+			t.Errorf("%+v chain = %q, want %q", tt.filters, got, tt.chain)
+		}
+		if got := tt.filters.Rate(); got != tt.rate {
+			t.Errorf("%+v rate = %v, want %v", tt.filters, got, tt.rate)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileInfo is what ffprobe found out about an audio file or link.
+type FileInfo struct {
+	Format   string // ffprobe's format name, e.g. "mp3" or "ogg".
+	Codec    string // Codec of the first audio stream, e.g. "opus".
+	Duration time.Duration
+	Title    string // From the file's tags, if it has them.
+	Artist   string
+}
+
+// Probe asks ffprobe what target, a file path or direct link, contains. It
+// fails when there's no audio stream in it.
+func Probe(ctx context.Context, ffprobePath, target string) (FileInfo, error) {
+	cmd := exec.CommandContext(ctx, ffprobePath, "-v", "error", "-print_format", "json", "-show_format", "-show_streams", target)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("probing with ffprobe: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return parseProbe(out)
+}
+
+// ffprobeOutput is the subset of ffprobe's JSON output the bot uses.
+type ffprobeOutput struct {
+	Streams []struct {
+		CodecType string `json:"codec_type"`
+		CodecName string `json:"codec_name"`
+	} `json:"streams"`
+	Format struct {
+		FormatName string            `json:"format_name"`
+		Duration   string            `json:"duration"`
+		Tags       map[string]string `json:"tags"`
+	} `json:"format"`
+}
+
+func parseProbe(data []byte) (FileInfo, error) {
+	var out ffprobeOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return FileInfo{}, fmt.Errorf("parsing ffprobe output: %w", err)
+	}
+
+	info := FileInfo{Format: out.Format.FormatName}
+	for _, stream := range out.Streams {
+		if stream.CodecType == "audio" {
+			info.Codec = stream.CodecName
+			break
+		}
+	}
+	if info.Codec == "" {
+		return FileInfo{}, fmt.Errorf("no audio found in %s file", info.Format)
+	}
+
+	if seconds, err := strconv.ParseFloat(out.Format.Duration, 64); err == nil {
+		info.Duration = time.Duration(seconds * float64(time.Second))
+	}
+	// Tag names vary in case between formats (title in MP3, TITLE in FLAC).
+	for key, value := range out.Format.Tags {
+		switch strings.ToLower(key) {
+		case "title":
+			info.Title = value
+		case "artist":
+			info.Artist = value
+		}
+	}
+	return info, nil
+}
@@ -0,0 +1,31 @@
+package audio
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseProbe(t *testing.T) {
+	out := `{
+		"streams": [
+			{"codec_type": "video", "codec_name": "mjpeg"},
+			{"codec_type": "audio", "codec_name": "flac"}
+		],
+		"format": {"format_name": "flac", "duration": "183.500000", "tags": {"TITLE": "Song", "ARTIST": "Band"}}
+	}`
+	info, err := parseProbe([]byte(out))
+	if err != nil {
+		t.Fatalf("parseProbe returned error: %v", err)
+	}
+	want := FileInfo{Format: "flac", Codec: "flac", Duration: 183500 * time.Millisecond, Title: "Song", Artist: "Band"}
+	if info != want {
+		t.Errorf("parseProbe = %+v, want %+v", info, want)
+	}
+}
+
+func TestParseProbeRejectsFilesWithoutAudio(t *testing.T) {
+	out := `{"streams": [{"codec_type": "video", "codec_name": "png"}], "format": {"format_name": "png_pipe"}}`
+	if _, err := parseProbe([]byte(out)); err == nil {
+		t.Error("parseProbe accepted an image")
+	}
+}
@@ -0,0 +1,150 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Result describes a video found by Search, listed by Playlist or looked up
+// with Info.
+type Result struct {
+	ID        string
+	Title     string
+	URL       string
+	Uploader  string
+	Duration  time.Duration // Zero for live streams or when unknown.
+	Thumbnail string        // URL of the video's thumbnail image, if known.
+	Playlist  string        // Title of the playlist the video came from, if any.
+}
+
+// Search asks yt-dlp for the top n YouTube results for a free-text query.
+func Search(ctx context.Context, ytdlpPath, query string, n int) ([]Result, error) {
+	target := fmt.Sprintf("ytsearch%d:%s", n, query)
+	cmd := exec.CommandContext(ctx, ytdlpPath, "--flat-playlist", "--dump-json", "--no-warnings", target)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("searching with yt-dlp: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return parseResults(bytes.NewReader(out))
+}
+
+// Playlist lists up to limit videos of a playlist without downloading them.
+func Playlist(ctx context.Context, ytdlpPath, url string, limit int) ([]Result, error) {
+	cmd := exec.CommandContext(ctx, ytdlpPath, "--flat-playlist", "--dump-json", "--no-warnings",
+		"--playlist-end", strconv.Itoa(limit), url)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing playlist with yt-dlp: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return parseResults(bytes.NewReader(out))
+}
+
+// Info fetches the details of a single video without downloading it.
+func Info(ctx context.Context, ytdlpPath, url string) (Result, error) {
+	cmd := exec.CommandContext(ctx, ytdlpPath, "--dump-json", "--no-playlist", "--skip-download", "--no-warnings", url)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return Result{}, fmt.Errorf("fetching video info with yt-dlp: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	results, err := parseResults(bytes.NewReader(out))
+	if err != nil {
+		return Result{}, err
+	}
+	if len(results) == 0 {
+		return Result{}, fmt.Errorf("yt-dlp returned no video for %s", url)
+	}
+	return results[0], nil
+}
+
+// IsPlaylist reports whether a link points at a YouTube playlist rather than a
+// single video. Videos opened from a playlist (watch?v=...&list=...) count as
+// single videos.
+func IsPlaylist(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	q := u.Query()
+	return q.Get("list") != "" && q.Get("v") == "" && !strings.HasPrefix(strings.ToLower(u.Host), "youtu.be")
+}
+
+// ytdlpEntry is the subset of yt-dlp's JSON output the bot uses.
+type ytdlpEntry struct {
+	ID         string  `json:"id"`
+	Title      string  `json:"title"`
+	URL        string  `json:"url"`
+	WebURL     string  `json:"webpage_url"`
+	Channel    string  `json:"channel"`
+	Uploader   string  `json:"uploader"`
+	Duration   float64 `json:"duration"`
+	Thumbnail  string  `json:"thumbnail"`
+	Thumbnails []struct {
+		URL string `json:"url"`
+	} `json:"thumbnails"` // Smallest first; flat listings only have these.
+	Playlist string `json:"playlist_title"`
+}
+
+func (e ytdlpEntry) result() Result {
+	r := Result{
+		ID:        e.ID,
+		Title:     e.Title,
+		URL:       e.WebURL,
+		Uploader:  e.Channel,
+		Duration:  time.Duration(e.Duration * float64(time.Second)),
+		Thumbnail: e.Thumbnail,
+		Playlist:  e.Playlist,
+	}
+	if r.URL == "" {
+		r.URL = e.URL
+	}
+	if r.URL == "" || !strings.HasPrefix(r.URL, "http") {
+		r.URL = "https://www.youtube.com/watch?v=" + e.ID
+	}
+	if r.Uploader == "" {
+		r.Uploader = e.Uploader
+	}
+	if r.Thumbnail == "" && len(e.Thumbnails) > 0 {
+		r.Thumbnail = e.Thumbnails[len(e.Thumbnails)-1].URL
+	}
+	return r
+}
+
+// parseResults reads yt-dlp's --dump-json output, one JSON object per line.
+func parseResults(r io.Reader) ([]Result, error) {
+	var results []Result
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var entry ytdlpEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("parsing yt-dlp output: %w", err)
+		}
+		if entry.ID == "" {
+			continue
+		}
+		results = append(results, entry.result())
+	}
+	return results, scanner.Err()
+}
@@ -0,0 +1,48 @@
+package audio
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseResults(t *testing.T) {
+	out := `{"id": "dQw4w9WgXcQ", "title": "Never Gonna Give You Up", "url": "https://www.youtube.com/watch?v=dQw4w9WgXcQ", "channel": "Rick Astley", "duration": 212.0, "thumbnail": "https://i.ytimg.com/vi/dQw4w9WgXcQ/maxresdefault.jpg"}
+{"id": "yPYZpwSpKmA", "title": "Together Forever", "uploader": "Rick Astley", "duration": null, "thumbnails": [{"url": "https://i.ytimg.com/vi/yPYZpwSpKmA/hqdefault.jpg?sqp=small"}, {"url": "https://i.ytimg.com/vi/yPYZpwSpKmA/hqdefault.jpg?sqp=large"}]}
+
+`
+	results, err := parseResults(strings.NewReader(out))
+	if err != nil {
+		t.Fatalf("parseResults returned error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+
+	first := results[0]
+	if first.Title != "Never Gonna Give You Up" || first.Uploader != "Rick Astley" || first.Duration != 212*time.Second ||
+		first.Thumbnail != "https://i.ytimg.com/vi/dQw4w9WgXcQ/maxresdefault.jpg" {
+		t.Errorf("first result = %+v", first)
+	}
+
+	second := results[1]
+	if second.URL != "https://www.youtube.com/watch?v=yPYZpwSpKmA" || second.Uploader != "Rick Astley" || second.Duration != 0 ||
+		second.Thumbnail != "https://i.ytimg.com/vi/yPYZpwSpKmA/hqdefault.jpg?sqp=large" {
+		t.Errorf("second result = %+v", second)
+	}
+}
+
+func TestIsPlaylist(t *testing.T) {
+	tests := map[string]bool{
+		"https://www.youtube.com/playlist?list=PL590L5WQmH8fJ54F369BLDSqIwcs-TCfs":     true,
+		"https://music.youtube.com/playlist?list=OLAK5uy_kh4ABCDEFG":                   true,
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ&list=PL590L5WQmH8fJ54F369BLDSqIw": false,
+		"https://youtu.be/dQw4w9WgXcQ?list=PL590L5WQmH8fJ54F369BLDSqIw":                false,
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ":                                  false,
+	}
+	for url, want := range tests {
+		if got := IsPlaylist(url); got != want {
+			t.Errorf("IsPlaylist(%q) = %v, want %v", url, got, want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Package audio turns songs into Opus frames for Discord, either by streaming
+// them straight from yt-dlp or by encoding a file that's already on disk.
+package audio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Source opens a stream of encoded audio (any format ffmpeg understands) for a
+// song URL.
+type Source interface {
+	Open(ctx context.Context, url string) (io.ReadCloser, error)
+}
+
+// SeekingSource is a Source that can start part way into a song without
+// fetching everything before it.
+type SeekingSource interface {
+	Source
+	OpenAt(ctx context.Context, url string, start time.Duration) (io.ReadCloser, error)
+}
+
+// YTDLP streams the best audio format of a video through yt-dlp's stdout.
+type YTDLP struct {
+	Path string // Path to the yt-dlp executable.
+}
+
+func (y YTDLP) Open(ctx context.Context, url string) (io.ReadCloser, error) {
+	return y.open(ctx, ytdlpArgs(url, 0))
+}
+
+// OpenAt has yt-dlp download only the section of the song after start.
+func (y YTDLP) OpenAt(ctx context.Context, url string, start time.Duration) (io.ReadCloser, error) {
+	return y.open(ctx, ytdlpArgs(url, start))
+}
+
+// ytdlpArgs streams url to stdout from start, or from the beginning when start
+// is zero.
+func ytdlpArgs(url string, start time.Duration) []string {
+	args := []string{"-f", "bestaudio", "--quiet", "--no-playlist", "-o", "-"}
+	if start > 0 {
+		args = append(args, "--download-sections", "*"+seconds(start)+"-inf")
+	}
+	return append(args, url)
+}
+
+func (y YTDLP) open(ctx context.Context, args []string) (io.ReadCloser, error) {
+	cmd := exec.CommandContext(ctx, y.Path, args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting yt-dlp: %w", err)
+	}
+	return &processReader{ReadCloser: stdout, cmd: cmd, stderr: &stderr}, nil
+}
+
+// processReader reads a process's stdout and reaps the process on Close.
+type processReader struct {
+	io.ReadCloser
+	cmd    *exec.Cmd
+	stderr *strings.Builder
+	input  io.Closer // What the process reads from, closed along with it.
+}
+
+func (p *processReader) Close() error {
+	p.ReadCloser.Close()
+	p.cmd.Process.Kill()
+	if p.input != nil {
+		p.input.Close()
+	}
+	if err := p.cmd.Wait(); err != nil && p.stderr.Len() > 0 {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(p.stderr.String()))
+	}
+	return nil
+}
+
+// File serves a local file for every URL. It stands in for yt-dlp when testing
+// the pipeline.
+type File struct {
+	Path string
+}
+
+func (f File) Open(ctx context.Context, url string) (io.ReadCloser, error) {
+	return os.Open(f.Path)
+}
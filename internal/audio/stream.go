@@ -0,0 +1,194 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jonas747/dca"
+)
+
+// normalVolume is the dca volume songs have always played at, which is what a
+// volume of 100% means. dca leaves audio unchanged at 256, far too loud next
+// to people talking. Its steps are too coarse for other volumes, so those are
+// a volume filter on top.
+const normalVolume = 10
+
+// Options controls how audio is encoded.
+type Options struct {
+	Volume  int           // Percent of normal loudness.
+	Filters Filters       // Effects applied to the song.
+	Start   time.Duration // Position in the song to start from, before Filters change its speed.
+	FFmpeg  string        // Path to ffmpeg for seeking, found on the PATH when empty.
+}
+
+func (o Options) encodeOptions() *dca.EncodeOptions {
+	options := *dca.StdEncodeOptions
+	options.RawOutput = true
+	options.Bitrate = 96
+	options.Application = "audio"
+	options.Volume = normalVolume
+	options.FrameRate = 48000
+	options.BufferedFrames = 100
+	options.AudioFilter = o.Filters.chain(o.Volume)
+	return &options
+}
+
+// Stream is a running encoder producing Opus frames.
+type Stream struct {
+	session *dca.EncodeSession
+	source  io.Closer // The piped input, nil when encoding a file.
+	first   []byte    // Frame read while checking the pipeline started.
+}
+
+// Open pipes a song from src through ffmpeg into the Opus encoder. It waits for
+// the first frame so a source that fails straight away (e.g. yt-dlp can't
+// handle the URL) is reported here and callers can fall back to downloading.
+func Open(ctx context.Context, src Source, url string, opts Options) (*Stream, error) {
+	r, err := openAt(ctx, src, url, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := dca.EncodeMem(r, opts.encodeOptions())
+	if err != nil {
+		r.Close()
+		return nil, fmt.Errorf("starting encoder: %w", err)
+	}
+
+	s := &Stream{session: session, source: r}
+	frame, err := s.read()
+	if err != nil {
+		closeErr := s.Close()
+		if errors.Is(err, io.EOF) {
+			err = fmt.Errorf("source produced no audio")
+		}
+		if closeErr != nil {
+			err = fmt.Errorf("%w (%v)", err, closeErr)
+		}
+		return nil, err
+	}
+	s.first = frame
+	return s, nil
+}
+
+// OpenFile encodes an audio file that's already on disk.
+func OpenFile(path string, opts Options) (*Stream, error) {
+	if opts.Start > 0 {
+		r, err := seek(context.Background(), opts.FFmpeg, nil, path, opts.Start)
+		if err != nil {
+			return nil, err
+		}
+		session, err := dca.EncodeMem(r, opts.encodeOptions())
+		if err != nil {
+			r.Close()
+			return nil, fmt.Errorf("encoding file with DCA: %w", err)
+		}
+		return &Stream{session: session, source: r}, nil
+	}
+
+	session, err := dca.EncodeFile(path, opts.encodeOptions())
+	if err != nil {
+		return nil, fmt.Errorf("encoding file with DCA: %w", err)
+	}
+	return &Stream{session: session}, nil
+}
+
+// OpusFrame returns the next 20ms Opus frame, or io.EOF at the end of the song.
+func (s *Stream) OpusFrame() ([]byte, error) {
+	if s.first != nil {
+		frame := s.first
+		s.first = nil
+		return frame, nil
+	}
+	return s.read()
+}
+
+func (s *Stream) read() ([]byte, error) {
+	for {
+		frame, err := s.session.OpusFrame()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				if encErr := s.session.Error(); encErr != nil {
+					return nil, fmt.Errorf("encoder: %w", encErr)
+				}
+			}
+			return nil, err
+		}
+		if len(frame) == 0 {
+			log.Println("⚠ Warning: Empty audio frame, DCA might be broken.")
+			continue
+		}
+		return frame, nil
+	}
+}
+
+// Close stops the encoder and its source.
+func (s *Stream) Close() error {
+	s.session.Cleanup()
+	if s.source != nil {
+		return s.source.Close()
+	}
+	return nil
+}
+
+// openAt opens a song from opts.Start. Sources that can't seek are streamed
+// from the beginning while ffmpeg skips to the start.
+func openAt(ctx context.Context, src Source, url string, opts Options) (io.ReadCloser, error) {
+	if opts.Start <= 0 {
+		return src.Open(ctx, url)
+	}
+	if s, ok := src.(SeekingSource); ok {
+		return s.OpenAt(ctx, url, opts.Start)
+	}
+	r, err := src.Open(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return seek(ctx, opts.FFmpeg, r, "pipe:0", opts.Start)
+}
+
+// seek decodes input from start with ffmpeg, reading it from r when it's a
+// pipe. dca can only seek after decoding, once the filters have changed the
+// song's speed, so positions are found here first, in the song's own time.
+// Files are seeked straight to start rather than decoded up to it.
+func seek(ctx context.Context, ffmpeg string, r io.ReadCloser, input string, start time.Duration) (io.ReadCloser, error) {
+	if ffmpeg == "" {
+		ffmpeg = "ffmpeg"
+	}
+	cmd := exec.CommandContext(ctx, ffmpeg, seekArgs(input, start)...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if r != nil {
+		cmd.Stdin = r
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err != nil {
+		if r != nil {
+			r.Close()
+		}
+		return nil, fmt.Errorf("starting ffmpeg to seek: %w", err)
+	}
+	return &processReader{ReadCloser: stdout, cmd: cmd, stderr: &stderr, input: r}, nil
+}
+
+// seekArgs has ffmpeg decode input to WAV from start. -ss goes before -i so it
+// seeks the input rather than the output.
+func seekArgs(input string, start time.Duration) []string {
+	return []string{"-v", "error", "-ss", seconds(start), "-i", input, "-map", "0:a", "-f", "wav", "pipe:1"}
+}
+
+// seconds formats d for ffmpeg and yt-dlp, to the millisecond.
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
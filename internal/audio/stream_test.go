@@ -0,0 +1,182 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func requireFFmpeg(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("ffmpeg not installed")
+	}
+}
+
+// sineFile writes a short test tone and returns its path.
+func sineFile(t *testing.T, seconds string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tone.ogg")
+	out, err := exec.Command("ffmpeg", "-v", "error", "-f", "lavfi", "-i", "sine=frequency=440:duration="+seconds, path).CombinedOutput()
+	if err != nil {
+		t.Fatalf("generating test tone: %v: %s", err, out)
+	}
+	return path
+}
+
+func TestOpenStreamsLocalFile(t *testing.T) {
+	requireFFmpeg(t)
+	src := File{Path: sineFile(t, "1")}
+
+	stream, err := Open(context.Background(), src, "https://www.youtube.com/watch?v=test", Options{Volume: 100})
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	defer stream.Close()
+
+	frames := 0
+	for {
+		_, err := stream.OpusFrame()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("OpusFrame returned error: %v", err)
+		}
+		frames++
+	}
+
+	// One second of audio is 50 frames of 20ms; allow for encoder padding.
+	if frames < 45 || frames > 55 {
+		t.Errorf("got %d frames, want about 50", frames)
+	}
+}
+
+func TestOpenReportsEmptySource(t *testing.T) {
+	requireFFmpeg(t)
+	path := filepath.Join(t.TempDir(), "empty.webm")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(context.Background(), File{Path: path}, "", Options{Volume: 100}); err == nil {
+		t.Error("Open on an empty source returned no error")
+	}
+}
+
+func TestOpenReportsSourceError(t *testing.T) {
+	src := File{Path: filepath.Join(t.TempDir(), "missing.webm")}
+	if _, err := Open(context.Background(), src, "", Options{Volume: 100}); err == nil {
+		t.Error("Open with a missing file returned no error")
+	}
+}
+
+// seekingFile records where it was asked to start.
+type seekingFile struct {
+	File
+	start time.Duration
+}
+
+func (s *seekingFile) OpenAt(ctx context.Context, url string, start time.Duration) (io.ReadCloser, error) {
+	s.start = start
+	return s.File.Open(ctx, url)
+}
+
+func TestOpenSeeksSourceInSongTime(t *testing.T) {
+	src := &seekingFile{File: File{Path: filepath.Join(t.TempDir(), "missing.webm")}}
+	opts := Options{Volume: 100, Filters: Filters{Nightcore: true}, Start: 83*time.Second + 250*time.Millisecond}
+	Open(context.Background(), src, "", opts)
+
+	// The position is in the song's time, however fast the filters play it.
+	if src.start != opts.Start {
+		t.Errorf("source asked to start at %v, want %v", src.start, opts.Start)
+	}
+	if got := opts.encodeOptions().StartTime; got != 0 {
+		t.Errorf("encoder seeks to %ds after the filters, want the input seeked instead", got)
+	}
+}
+
+func TestSeekArgs(t *testing.T) {
+	args := seekArgs("song.webm", 83*time.Second+250*time.Millisecond)
+	ss, in := slices.Index(args, "-ss"), slices.Index(args, "-i")
+	if ss < 0 || in < 0 || ss > in {
+		t.Fatalf("ffmpeg args %q don't seek the input", args)
+	}
+	if args[ss+1] != "83.250" || args[in+1] != "song.webm" {
+		t.Errorf("ffmpeg args %q, want -ss 83.250 -i song.webm", args)
+	}
+}
+
+func TestSeekRunsConfiguredFFmpeg(t *testing.T) {
+	ffmpeg := filepath.Join(t.TempDir(), "ffmpeg.exe")
+	_, err := OpenFile("song.webm", Options{Volume: 100, Start: time.Second, FFmpeg: ffmpeg})
+	if err == nil || !strings.Contains(err.Error(), ffmpeg) {
+		t.Errorf("OpenFile with a missing ffmpeg at %s returned %v, want an error naming it", ffmpeg, err)
+	}
+}
+
+func TestYTDLPArgs(t *testing.T) {
+	url := "https://www.youtube.com/watch?v=test"
+	if args := ytdlpArgs(url, 0); slices.Contains(args, "--download-sections") || args[len(args)-1] != url {
+		t.Errorf("yt-dlp args from the start %q", args)
+	}
+	args := ytdlpArgs(url, 90*time.Minute+500*time.Millisecond)
+	i := slices.Index(args, "--download-sections")
+	if i < 0 || args[i+1] != "*5400.500-inf" || args[len(args)-1] != url {
+		t.Errorf("yt-dlp args %q, want --download-sections *5400.500-inf before the URL", args)
+	}
+}
+
+func TestOpenFileSeeks(t *testing.T) {
+	requireFFmpeg(t)
+	stream, err := OpenFile(sineFile(t, "2"), Options{Volume: 100, Start: 1500 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("OpenFile returned error: %v", err)
+	}
+	defer stream.Close()
+
+	frames := 0
+	for {
+		if _, err := stream.OpusFrame(); err != nil {
+			break
+		}
+		frames++
+	}
+	// Half a second is left after the seek, 25 frames.
+	if frames < 20 || frames > 30 {
+		t.Errorf("got %d frames, want about 25", frames)
+	}
+}
+
+func TestVolumeMatchesNormalLoudness(t *testing.T) {
+	tests := []struct {
+		volume  int
+		filters Filters
+		chain   string
+	}{
+		{100, Filters{}, ""},
+		{50, Filters{}, "volume=0.5"},
+		{55, Filters{}, "volume=0.55"},
+		{1, Filters{}, "volume=0.01"},
+		{137, Filters{}, "volume=1.37"},
+		{200, Filters{}, "volume=2"},
+		{0, Filters{}, "volume=0"},
+		{75, Filters{Normalize: true}, "loudnorm=I=-16:TP=-1.5:LRA=11,volume=0.75"},
+	}
+	for _, tt := range tests {
+		options := (Options{Volume: tt.volume, Filters: tt.filters}).encodeOptions()
+		if options.Volume != normalVolume {
+			t.Errorf("volume %d%% encodes at dca volume %d, want %d", tt.volume, options.Volume, normalVolume)
+		}
+		if options.AudioFilter != tt.chain {
+			t.Errorf("volume %d%% with %+v filters with %q, want %q", tt.volume, tt.filters, options.AudioFilter, tt.chain)
+		}
+	}
+}
@@ -1,37 +1,103 @@
 package bot
 
 import (
+	"errors"
 	"fmt"
 	"log"
-	"strings"
+	"time"
 
 	"github.com/AjStraight619/discord-bot/internal/apiclients"
+	"github.com/AjStraight619/discord-bot/internal/args"
+	"github.com/AjStraight619/discord-bot/internal/storage"
 	"github.com/bwmarrin/discordgo"
 )
 
-type AICommand struct{}
+const (
+	// conversationLength is the number of messages of history kept per channel.
+	conversationLength = 20
+	// conversationTTL is how long a channel can be quiet before its AI
+	// conversation starts over.
+	conversationTTL = time.Hour
+)
 
-func (ai AICommand) Execute(b *BotController, msg *discordgo.MessageCreate, options []string) {
-	if len(options) == 0 {
-		b.Session.ChannelMessageSend(msg.ChannelID, "Please enter a question! Example: `!ai How does the quadratic formula work?`")
-		return
-	}
+type AICommand struct{}
 
-	b.ChatGPTResponse(options, msg)
+func (ai AICommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	b.ChatGPTResponse(opts.String("question"), msg)
 }
 
 func (ai AICommand) Help() string {
-	return "!ai <question> - Ask the AI a question."
+	return "Ask the AI a question."
+}
+
+func (ai AICommand) Args() []args.Spec {
+	return []args.Spec{
+		{Name: "question", Description: "What you want to ask", Kind: args.Text, Required: true},
+	}
 }
 
-func (b *BotController) ChatGPTResponse(options []string, msg *discordgo.MessageCreate) {
+func (ai AICommand) Category() string { return "AI" }
 
-	query := strings.Join(options, " ")
-	response, err := apiclients.GetAIResponse(query)
+func (ai AICommand) Examples() []string {
+	return []string{"!ai How does the quadratic formula work?"}
+}
+
+func (ai AICommand) Deferred() bool { return true }
+
+func (b *BotController) ChatGPTResponse(query string, msg *discordgo.MessageCreate) {
+	conversation := b.loadConversation(msg.ChannelID)
+
+	var history []apiclients.ChatMessage
+	for _, m := range conversation.Messages {
+		history = append(history, apiclients.ChatMessage{Role: m.Role, Content: m.Content})
+	}
+
+	response, err := apiclients.GetAIChatResponse(history, query)
 	if err != nil {
 		log.Printf("Error getting AI response: %v", err)
-		b.Session.ChannelMessageSend(msg.ChannelID, "Error fetching AI response. Please try again later.")
+		b.reply(msg, "Error fetching AI response. Please try again later.")
+		return
+	}
+	b.reply(msg, fmt.Sprintf("🤖 **ChatGPT:** %s", response))
+
+	now := time.Now()
+	conversation.Messages = append(conversation.Messages,
+		storage.ConversationMessage{Role: "user", Content: query, Time: now},
+		storage.ConversationMessage{Role: "assistant", Content: response, Time: now},
+	)
+	b.saveConversation(msg.ChannelID, conversation)
+}
+
+// loadConversation returns a channel's recent AI history, or an empty one when
+// the channel has been quiet for longer than conversationTTL.
+func (b *BotController) loadConversation(channelID string) storage.Conversation {
+	if b.Store == nil {
+		return storage.Conversation{}
+	}
+
+	conversation, err := storage.NewRepository[storage.Conversation](b.Store, storage.BucketConversations).Get(channelID)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			log.Printf("Error loading conversation for channel %s: %v", channelID, err)
+		}
+		return storage.Conversation{}
+	}
+
+	if n := len(conversation.Messages); n > 0 && time.Since(conversation.Messages[n-1].Time) > conversationTTL {
+		return storage.Conversation{}
+	}
+	return conversation
+}
+
+func (b *BotController) saveConversation(channelID string, conversation storage.Conversation) {
+	if b.Store == nil {
 		return
 	}
-	b.Session.ChannelMessageSend(msg.ChannelID, fmt.Sprintf("🤖 **ChatGPT:** %s", response))
+	if n := len(conversation.Messages); n > conversationLength {
+		conversation.Messages = conversation.Messages[n-conversationLength:]
+	}
+	err := storage.NewRepository[storage.Conversation](b.Store, storage.BucketConversations).Put(channelID, conversation)
+	if err != nil {
+		log.Printf("Error saving conversation for channel %s: %v", channelID, err)
+	}
 }
@@ -1,13 +1,20 @@
 package bot
 
 import (
+	"sort"
+	"strings"
+
+	"github.com/AjStraight619/discord-bot/internal/args"
 	"github.com/bwmarrin/discordgo"
 )
 
-// Command represents an executable bot command.
+// Command represents an executable bot command. Args describes the arguments the
+// command accepts; they are parsed and validated before Execute is called and
+// also describe the command's slash command options.
 type Command interface {
-	Execute(b *BotController, msg *discordgo.MessageCreate, options []string)
+	Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values)
 	Help() string
+	Args() []args.Spec
 }
 
 // CommandRegistry holds available commands.
@@ -29,3 +36,18 @@ func (cr *CommandRegistry) Get(name string) (Command, bool) {
 	cmd, ok := cr.commands[name]
 	return cmd, ok
 }
+
+// commandName normalizes user input such as "Play" or "!play" to a registry name.
+func commandName(name string) string {
+	return "!" + strings.TrimPrefix(strings.ToLower(name), "!")
+}
+
+// Names returns the registered command names in alphabetical order.
+func (cr *CommandRegistry) Names() []string {
+	names := make([]string, 0, len(cr.commands))
+	for name := range cr.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,60 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// handleComponent routes button presses by the prefix of their custom ID, e.g.
+// "help:2" goes to the help pager with the payload "2".
+func (b *BotController) handleComponent(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	customID := i.MessageComponentData().CustomID
+	prefix, payload, _ := strings.Cut(customID, ":")
+
+	switch prefix {
+	case "help":
+		b.handleHelpPage(s, i, payload)
+	case "queue":
+		b.handleQueuePage(s, i, payload)
+	case "pick":
+		b.handlePick(s, i, payload)
+	default:
+		log.Printf("Unknown component: %s", customID)
+	}
+}
+
+// pagerButtons returns Previous/Next buttons whose custom IDs carry the page
+// they lead to, e.g. "help:3". There are no buttons when everything fits on
+// one page.
+func pagerButtons(prefix string, page, pages int) []discordgo.MessageComponent {
+	if pages <= 1 {
+		return nil
+	}
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    "◀ Previous",
+				Style:    discordgo.SecondaryButton,
+				CustomID: fmt.Sprintf("%s:%d", prefix, page-1),
+				Disabled: page == 0,
+			},
+			discordgo.Button{
+				Label:    "Next ▶",
+				Style:    discordgo.SecondaryButton,
+				CustomID: fmt.Sprintf("%s:%d", prefix, page+1),
+				Disabled: page == pages-1,
+			},
+		}},
+	}
+}
+
+// interactionUser returns the user behind an interaction in a guild or a DM.
+func interactionUser(i *discordgo.InteractionCreate) *discordgo.User {
+	if i.Member != nil {
+		return i.Member.User
+	}
+	return i.User
+}
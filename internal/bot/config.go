@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/AjStraight619/discord-bot/internal/args"
+	"github.com/AjStraight619/discord-bot/internal/storage"
+	"github.com/bwmarrin/discordgo"
+)
+
+// ConfigCommand shows and changes the guild's settings.
+type ConfigCommand struct{}
+
+func (cc ConfigCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	action := opts.String("action")
+	if action == "list" {
+		b.reply(msg, b.formatGuildSettings(msg.GuildID))
+		return
+	}
+
+	key := opts.String("key")
+	if key == "" {
+		b.reply(msg, fmt.Sprintf("⚠ Please specify a setting, e.g. `!config %s volume`.", action))
+		return
+	}
+	def, _ := lookupGuildSetting(key)
+
+	switch action {
+	case "get":
+		b.reply(msg, fmt.Sprintf("`%s` is %s.", key, def.show(b, msg.GuildID)))
+
+	case "reset":
+		if err := b.updateGuildSettings(msg.GuildID, def.reset); err != nil {
+			log.Printf("Error resetting %s for guild %s: %v", key, msg.GuildID, err)
+			b.reply(msg, "⚠ Couldn't save the setting, please try again.")
+			return
+		}
+		b.reply(msg, fmt.Sprintf("✅ Reset `%s` to %s.", key, def.show(b, msg.GuildID)))
+
+	case "set":
+		settings := b.guildSettings(msg.GuildID)
+		if err := applyGuildSetting(def, &settings, opts.String("value")); err != nil {
+			b.reply(msg, fmt.Sprintf("⚠ %s.", err))
+			return
+		}
+		err := b.updateGuildSettings(msg.GuildID, func(s *storage.GuildSettings) { *s = settings })
+		if err != nil {
+			log.Printf("Error saving %s for guild %s: %v", key, msg.GuildID, err)
+			b.reply(msg, "⚠ Couldn't save the setting, please try again.")
+			return
+		}
+		b.reply(msg, fmt.Sprintf("✅ Set `%s` to %s.", key, def.show(b, msg.GuildID)))
+	}
+}
+
+func (cc ConfigCommand) Help() string {
+	return "Shows or changes this server's settings, such as the prefix and music volume."
+}
+
+func (cc ConfigCommand) Args() []args.Spec {
+	return []args.Spec{
+		{Name: "action", Description: "What to do", Kind: args.Enum, Required: true, Choices: []string{"get", "set", "reset", "list"}},
+		{Name: "key", Description: "Setting to show or change", Kind: args.Enum, Choices: guildSettingKeys()},
+		{Name: "value", Description: "New value for set", Kind: args.Text},
+	}
+}
+
+func (cc ConfigCommand) Permission() int64 { return discordgo.PermissionManageServer }
+
+func (cc ConfigCommand) Category() string { return "Admin" }
+
+func (cc ConfigCommand) Examples() []string {
+	return []string{"!config list", "!config get prefix", "!config set volume 80", "!config set announce-channel #music", "!config reset timeout"}
+}
+
+// applyGuildSetting parses value as the setting def describes, checks it and
+// sets it on settings.
+func applyGuildSetting(def guildSetting, settings *storage.GuildSettings, value string) error {
+	spec := def.spec
+	spec.Required = true
+	words, err := args.Split(value)
+	if err != nil {
+		return err
+	}
+	values, err := args.Parse([]args.Spec{spec}, words)
+	if err != nil {
+		return err
+	}
+	return def.set(settings, values)
+}
+
+func (b *BotController) formatGuildSettings(guildID string) string {
+	var sb strings.Builder
+	sb.WriteString("⚙ **Server settings**\n")
+	for _, def := range guildSettingDefs {
+		fmt.Fprintf(&sb, "`%s`: %s — %s\n", def.spec.Name, def.show(b, guildID), def.spec.Description)
+	}
+	return sb.String()
+}
@@ -4,24 +4,38 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/AjStraight619/discord-bot/internal/args"
+	"github.com/AjStraight619/discord-bot/internal/audio"
+	"github.com/AjStraight619/discord-bot/internal/speech"
+	"github.com/AjStraight619/discord-bot/internal/storage"
 	"github.com/bwmarrin/discordgo"
 )
 
-// BotController holds the state for your bot.
+// BotController holds the state for your bot. Voice and music state lives in
+// per-guild sessions so servers don't trample each other.
 type BotController struct {
-	VoiceTextChannelID string
-	Session            *discordgo.Session
-	VoiceConn          *discordgo.VoiceConnection
-	isBotInChannel     bool
-	musicQueue         []*Song
-	isPlaying          bool
-	LastHeard          time.Time
-	CommandRegistry    *CommandRegistry
-	inactivityTimer    *time.Timer
-	TimeoutDuration    time.Duration
-	VoiceHandler       *VoiceCommandHandler
+	Session         *discordgo.Session
+	Sessions        *SessionManager
+	LastHeard       time.Time
+	CommandRegistry *CommandRegistry
+	Permissions     *PermissionRules
+	Store           storage.Store
+	TimeoutDuration time.Duration
+	VoiceHandler    *VoiceCommandHandler
+	AudioSource     audio.Source      // Where songs are streamed from, yt-dlp when nil.
+	AudioCache      *audio.Cache      // Downloaded and streamed songs, nil disables caching.
+	Downloads       *audio.Downloader // Prefetches and fallback downloads, nil disables both.
+	MusicLibrary    string            // Directory of local music for !play local:, empty disables it.
+	MaxFileBytes    int64             // Largest upload or library file !play accepts, zero for no limit.
+	Speech          speech.Recognizer // Speech-to-text for !transcribe, nil disables it.
+	RecordingsDir   string            // Where !record saves recordings.
+	RecordingsURL   string            // Public URL of RecordingsDir, empty when it isn't served.
+
+	interactions sync.Map // Synthetic message ID -> *pendingInteraction.
+	pickers      sync.Map // Search message ID -> *songPicker.
 }
 
 func (b *BotController) MessageHandler(s *discordgo.Session, msg *discordgo.MessageCreate) {
@@ -29,42 +43,58 @@ func (b *BotController) MessageHandler(s *discordgo.Session, msg *discordgo.Mess
 		return
 	}
 
-	// Set the text channel ID for voice responses.
-	b.VoiceTextChannelID = msg.ChannelID
-
 	msgContent := strings.TrimSpace(msg.Content)
 
-	if !strings.HasPrefix(msgContent, "!") {
+	prefix := b.prefixFor(msg.GuildID)
+	if !strings.HasPrefix(msgContent, prefix) {
 		return // Ignore messages that are not commands
 	}
 
-	b.ResetTimeout()
+	// Set the text channel ID for voice responses.
+	b.Sessions.Get(msg.GuildID).SetTextChannel(msg.ChannelID)
 
-	msgParts := strings.Fields(msgContent)
+	b.ResetTimeout(msg.GuildID)
 
-	var actions []string
-	var options []string
+	invocations, err := args.Commands(msgContent, prefix)
+	if err != nil {
+		b.displayCmdError(msg.ChannelID, fmt.Sprintf("⚠ %s", err))
+		return
+	}
 
-	for _, part := range msgParts {
-		if strings.HasPrefix(part, "!") {
-			actions = append(actions, part)
-		} else {
-			options = append(options, part)
-		}
+	// Process each command using the command registry.
+	for _, inv := range invocations {
+		inv.Name = commandName(strings.TrimPrefix(inv.Name, prefix))
+		log.Printf("Parsed command: %s %v", inv.Name, inv.Words)
+		b.runCommand(msg, inv)
+	}
+}
+
+// runCommand looks up, authorizes and parses one command from a message and
+// runs it in the background.
+func (b *BotController) runCommand(msg *discordgo.MessageCreate, inv args.Invocation) {
+	cmd, ok := b.CommandRegistry.Get(inv.Name)
+	if !ok {
+		log.Printf("Unknown command: %s", inv.Name)
+		b.displayCmdError(msg.ChannelID, fmt.Sprintf("Unknown command: %s", inv.Name))
+		return
 	}
 
-	log.Printf("Parsed Actions: %v", actions)
-	log.Printf("Parsed Options: %v", options)
+	if ok, reason := b.authorize(msg.GuildID, msg.ChannelID, msg.Author.ID, inv.Name, cmd); !ok {
+		b.displayCmdError(msg.ChannelID, reason)
+		return
+	}
 
-	// Process each action using the command registry.
-	for _, action := range actions {
-		if cmd, ok := b.CommandRegistry.Get(action); ok {
-			go cmd.Execute(b, msg, options)
-		} else {
-			log.Printf("Unknown command: %s", action)
-			b.displayCmdError(msg.ChannelID, fmt.Sprintf("Unknown command: %s", action))
-		}
+	opts, err := args.Parse(cmd.Args(), inv.Words)
+	if err != nil {
+		b.displayCmdError(msg.ChannelID, usageError(inv.Name, cmd, err))
+		return
 	}
+	go cmd.Execute(b, msg, opts)
+}
+
+// usageError formats an argument error along with the command's usage line.
+func usageError(name string, cmd Command, err error) string {
+	return fmt.Sprintf("⚠ %s\nUsage: `%s`", err, args.Usage(name, cmd.Args()))
 }
 
 // InitCommands initializes the command registry and registers commands.
@@ -73,18 +103,41 @@ func (b *BotController) InitCommands() {
 	b.CommandRegistry.Register("!news", NewsCommand{})
 	b.CommandRegistry.Register("!ai", AICommand{})
 	b.CommandRegistry.Register("!play", SongCommand{})
+	b.CommandRegistry.Register("!skip", SkipCommand{})
+	b.CommandRegistry.Register("!pause", PauseCommand{})
+	b.CommandRegistry.Register("!resume", ResumeCommand{})
+	b.CommandRegistry.Register("!stop", StopCommand{})
+	b.CommandRegistry.Register("!np", NowPlayingCommand{})
+	b.CommandRegistry.Register("!seek", SeekCommand{})
+	b.CommandRegistry.Register("!forward", ForwardCommand{})
+	b.CommandRegistry.Register("!rewind", RewindCommand{})
+	b.CommandRegistry.Register("!queue", QueueCommand{})
+	b.CommandRegistry.Register("!remove", RemoveCommand{})
+	b.CommandRegistry.Register("!move", MoveCommand{})
+	b.CommandRegistry.Register("!shuffle", ShuffleCommand{})
+	b.CommandRegistry.Register("!clear", ClearCommand{})
+	b.CommandRegistry.Register("!loop", LoopCommand{})
+	b.CommandRegistry.Register("!playlist", PlaylistCommand{})
+	b.CommandRegistry.Register("!volume", VolumeCommand{})
+	b.CommandRegistry.Register("!filter", FilterCommand{})
+	b.CommandRegistry.Register("!library", LibraryCommand{})
 	b.CommandRegistry.Register("!listen", ListenCommand{})
+	b.CommandRegistry.Register("!transcribe", TranscribeCommand{})
+	b.CommandRegistry.Register("!record", RecordCommand{})
 	b.CommandRegistry.Register("!join", JoinCommand{})
 	b.CommandRegistry.Register("!leave", LeaveCommand{})
 	b.CommandRegistry.Register("!sports", SportsCommand{})
 	b.CommandRegistry.Register("!timeout", TimeoutCommand{})
+	b.CommandRegistry.Register("!help", HelpCommand{})
+	b.CommandRegistry.Register("!perms", PermsCommand{})
+	b.CommandRegistry.Register("!config", ConfigCommand{})
 }
 
 func (b *BotController) displayCmdError(channelID string, msg string) {
 	b.Session.ChannelMessageSend(channelID, msg)
 }
 
-// joinUserChannel is a helper to join a voice channel.
+// joinUserChannel is a helper to join the voice channel a user is in.
 func (b *BotController) joinUserChannel(guildID, userID string, mute, deafened bool) (*discordgo.VoiceConnection, error) {
 	guild, err := b.Session.State.Guild(guildID)
 	if err != nil {
@@ -92,19 +145,59 @@ func (b *BotController) joinUserChannel(guildID, userID string, mute, deafened b
 	}
 	for _, vs := range guild.VoiceStates {
 		if vs.UserID == userID {
-			vc, err := b.Session.ChannelVoiceJoin(guildID, vs.ChannelID, mute, deafened)
-			if err != nil {
-				return nil, fmt.Errorf("failed to join voice channel: %w", err)
-			}
-			b.VoiceConn = vc
-			// NOTE: Probably better to just attach vc to struct instead of returning it.
-			b.isBotInChannel = true
-			return vc, nil
+			return b.joinChannel(guildID, vs.ChannelID, mute, deafened)
 		}
 	}
 	return nil, fmt.Errorf("user not in a voice channel")
 }
 
+// joinChannel joins a voice channel and records it on the guild's session,
+// saving it so a restart of the bot rejoins it.
+func (b *BotController) joinChannel(guildID, channelID string, mute, deafened bool) (*discordgo.VoiceConnection, error) {
+	vc, err := b.Session.ChannelVoiceJoin(guildID, channelID, mute, deafened)
+	if err != nil {
+		return nil, fmt.Errorf("failed to join voice channel: %w", err)
+	}
+	gs := b.Sessions.Get(guildID)
+	gs.mu.Lock()
+	gs.VoiceConn = vc
+	gs.VoiceChannelID = channelID
+	gs.isBotInChannel = true
+	gs.voiceMuted = mute
+	gs.mu.Unlock()
+	b.saveQueue(gs)
+
+	// Nobody may be there when rejoining a saved channel after a restart.
+	b.checkAlone(gs, channelID)
+	return vc, nil
+}
+
+// joinForPlayback keeps using the bot's voice connection while it's up and
+// unmuted. Otherwise it joins the requester's voice channel, falling back to the
+// guild's last voice channel when there is no requester (e.g. when resuming
+// after a restart) or they have left voice.
+func (b *BotController) joinForPlayback(gs *GuildSession, userID string) (*discordgo.VoiceConnection, error) {
+	gs.mu.Lock()
+	vc, muted := gs.VoiceConn, gs.voiceMuted
+	gs.mu.Unlock()
+	if vc != nil && !muted && voiceReady(vc) {
+		return vc, nil
+	}
+	if userID != "" {
+		vc, err := b.joinUserChannel(gs.GuildID, userID, false, !gs.listening())
+		if err == nil {
+			return vc, nil
+		}
+		log.Printf("Couldn't join requester's voice channel: %v", err)
+	}
+
+	channelID := gs.VoiceChannel()
+	if channelID == "" {
+		return nil, fmt.Errorf("no voice channel to join")
+	}
+	return b.joinChannel(gs.GuildID, channelID, false, !gs.listening())
+}
+
 // func extractCommands(message string) []string {
 // 	re := regexp.MustCompile(`!([a-zA-Z]+)`)
 // 	matches := re.FindAllString(message, -1)
@@ -116,7 +209,7 @@ func (b *BotController) joinUserChannel(guildID, userID string, mute, deafened b
 // 	ticker := time.NewTicker(10 * time.Second)
 // 	defer ticker.Stop()
 // 	for range ticker.C {
-// 		if b.isBotInChannel && time.Since(b.LastHeard) > 60*time.Second {
+// 		if gs.isBotInChannel && time.Since(b.LastHeard) > 60*time.Second {
 // 			log.Println("No audio detected for over 60 seconds. Leaving voice channel...")
 // 			b.LeaveVoiceChannel()
 // 			return
@@ -0,0 +1,202 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AjStraight619/discord-bot/internal/args"
+	"github.com/bwmarrin/discordgo"
+)
+
+// SkipCommand skips the current song.
+type SkipCommand struct{}
+
+func (sc SkipCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	song := b.Sessions.Get(msg.GuildID).player.Skip()
+	if song == nil {
+		b.reply(msg, "⚠ Nothing is playing.")
+		return
+	}
+	b.reply(msg, fmt.Sprintf("⏭ Skipped %s.", song.Name()))
+}
+
+func (sc SkipCommand) Help() string { return "Skips the current song." }
+
+func (sc SkipCommand) Args() []args.Spec { return nil }
+
+func (sc SkipCommand) Category() string { return "Music" }
+
+func (sc SkipCommand) Examples() []string { return []string{"!skip"} }
+
+// PauseCommand pauses the current song.
+type PauseCommand struct{}
+
+func (pc PauseCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	if !b.Sessions.Get(msg.GuildID).player.Pause() {
+		b.reply(msg, "⚠ Nothing is playing, or it's already paused.")
+		return
+	}
+	b.reply(msg, "⏸ Paused.")
+}
+
+func (pc PauseCommand) Help() string { return "Pauses the current song." }
+
+func (pc PauseCommand) Args() []args.Spec { return nil }
+
+func (pc PauseCommand) Category() string { return "Music" }
+
+func (pc PauseCommand) Examples() []string { return []string{"!pause"} }
+
+// ResumeCommand resumes a paused song.
+type ResumeCommand struct{}
+
+func (rc ResumeCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	if !b.Sessions.Get(msg.GuildID).player.Resume() {
+		b.reply(msg, "⚠ Nothing is paused.")
+		return
+	}
+	b.reply(msg, "▶ Resumed.")
+}
+
+func (rc ResumeCommand) Help() string { return "Resumes a paused song." }
+
+func (rc ResumeCommand) Args() []args.Spec { return nil }
+
+func (rc ResumeCommand) Category() string { return "Music" }
+
+func (rc ResumeCommand) Examples() []string { return []string{"!resume"} }
+
+// StopCommand stops playback and clears the queue.
+type StopCommand struct{}
+
+func (sc StopCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	if b.stopPlayback(b.Sessions.Get(msg.GuildID)) == nil {
+		b.reply(msg, "⚠ Nothing is playing.")
+		return
+	}
+	b.reply(msg, "⏹ Stopped playback and cleared the queue.")
+}
+
+func (sc StopCommand) Help() string { return "Stops playback and clears the queue." }
+
+func (sc StopCommand) Args() []args.Spec { return nil }
+
+func (sc StopCommand) Category() string { return "Music" }
+
+func (sc StopCommand) Examples() []string { return []string{"!stop"} }
+
+// NowPlayingCommand shows the current song and how far into it playback is.
+type NowPlayingCommand struct{}
+
+func (nc NowPlayingCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	song, elapsed, total, paused := b.Sessions.Get(msg.GuildID).player.Status()
+	if song == nil {
+		b.reply(msg, "⚠ Nothing is playing.")
+		return
+	}
+
+	state := "🎶 Now playing"
+	if paused {
+		state = "⏸ Paused"
+	}
+	b.replyComplex(msg, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{nowPlayingEmbed(state, song, elapsed, total)},
+	})
+}
+
+func (nc NowPlayingCommand) Help() string { return "Shows the current song and its progress." }
+
+func (nc NowPlayingCommand) Args() []args.Spec { return nil }
+
+func (nc NowPlayingCommand) Category() string { return "Music" }
+
+func (nc NowPlayingCommand) Examples() []string { return []string{"!np"} }
+
+// SeekCommand jumps to a position in the current song.
+type SeekCommand struct{}
+
+func (sc SeekCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	to := opts.Duration("position", 0)
+	b.seek(msg, func(time.Duration) time.Duration { return to })
+}
+
+func (sc SeekCommand) Help() string { return "Jumps to a position in the current song." }
+
+func (sc SeekCommand) Args() []args.Spec {
+	return []args.Spec{
+		{Name: "position", Description: "Where to jump to, e.g. 1:30", Kind: args.Duration, Required: true},
+	}
+}
+
+func (sc SeekCommand) Category() string { return "Music" }
+
+func (sc SeekCommand) Examples() []string { return []string{"!seek 1:30", "!seek 1:02:00"} }
+
+// ForwardCommand skips ahead in the current song.
+type ForwardCommand struct{}
+
+func (fc ForwardCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	by := opts.Duration("seconds", seekStep)
+	b.seek(msg, func(elapsed time.Duration) time.Duration { return elapsed + by })
+}
+
+func (fc ForwardCommand) Help() string {
+	return "Skips ahead in the current song, 10 seconds by default."
+}
+
+func (fc ForwardCommand) Args() []args.Spec {
+	return []args.Spec{
+		{Name: "seconds", Description: "How far to skip ahead", Kind: args.Duration},
+	}
+}
+
+func (fc ForwardCommand) Category() string { return "Music" }
+
+func (fc ForwardCommand) Examples() []string {
+	return []string{"!forward", "!forward 30", "!forward 5m"}
+}
+
+// RewindCommand goes back in the current song.
+type RewindCommand struct{}
+
+func (rc RewindCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	by := opts.Duration("seconds", seekStep)
+	b.seek(msg, func(elapsed time.Duration) time.Duration { return max(elapsed-by, 0) })
+}
+
+func (rc RewindCommand) Help() string { return "Goes back in the current song, 10 seconds by default." }
+
+func (rc RewindCommand) Args() []args.Spec {
+	return []args.Spec{
+		{Name: "seconds", Description: "How far to go back", Kind: args.Duration},
+	}
+}
+
+func (rc RewindCommand) Category() string { return "Music" }
+
+func (rc RewindCommand) Examples() []string { return []string{"!rewind", "!rewind 30"} }
+
+// seekStep is how far !forward and !rewind move without an argument.
+const seekStep = 10 * time.Second
+
+// seek moves playback of the current song to the position target picks based
+// on the current one.
+func (b *BotController) seek(msg *discordgo.MessageCreate, target func(elapsed time.Duration) time.Duration) {
+	gs := b.Sessions.Get(msg.GuildID)
+	song, elapsed, total, _ := gs.player.Status()
+	if song == nil {
+		b.reply(msg, "⚠ Nothing is playing.")
+		return
+	}
+
+	to := target(elapsed)
+	if total > 0 && to >= total {
+		b.reply(msg, fmt.Sprintf("⚠ **%s** is only %s long.", song.Name(), formatDuration(total)))
+		return
+	}
+	if !gs.player.Seek(to) {
+		b.reply(msg, "⚠ The song hasn't started yet, try again in a moment.")
+		return
+	}
+	b.reply(msg, fmt.Sprintf("⏩ Jumped to %s.", formatDuration(to)))
+}
@@ -0,0 +1,166 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/AjStraight619/discord-bot/internal/args"
+	"github.com/AjStraight619/discord-bot/internal/audio"
+	"github.com/AjStraight619/discord-bot/internal/storage"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Names of the effects !filter can toggle.
+const (
+	filterBassBoost = "bassboost"
+	filterNightcore = "nightcore"
+	filterNormalize = "normalize"
+)
+
+// VolumeCommand shows or changes the music volume, including for the song
+// that's playing.
+type VolumeCommand struct{}
+
+func (vc VolumeCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	if !opts.Has("percent") {
+		b.reply(msg, fmt.Sprintf("🔊 Volume is %d%%.", b.volumeFor(msg.GuildID)))
+		return
+	}
+
+	volume := opts.Int("percent", defaultVolume)
+	err := b.updateGuildSettings(msg.GuildID, func(settings *storage.GuildSettings) {
+		settings.Volume = &volume
+	})
+	if err != nil {
+		log.Printf("Error saving volume for guild %s: %v", msg.GuildID, err)
+		b.reply(msg, "⚠ Couldn't save the volume, please try again.")
+		return
+	}
+
+	// The encoder bakes the volume in, so restart it from the current position.
+	b.Sessions.Get(msg.GuildID).player.Restart()
+	b.reply(msg, fmt.Sprintf("🔊 Volume set to %d%%.", volume))
+}
+
+func (vc VolumeCommand) Help() string { return "Shows or changes the music volume." }
+
+func (vc VolumeCommand) Args() []args.Spec {
+	return []args.Spec{
+		{Name: "percent", Description: "New volume, 100 is normal", Kind: args.Int, Min: 0, Max: 200},
+	}
+}
+
+func (vc VolumeCommand) Category() string { return "Music" }
+
+func (vc VolumeCommand) Examples() []string { return []string{"!volume", "!volume 50", "!volume 150"} }
+
+// FilterCommand turns audio effects on or off for the guild's next songs.
+type FilterCommand struct{}
+
+func (fc FilterCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	name := opts.String("effect")
+	value := strings.ToLower(opts.String("value"))
+	if name == "" {
+		b.reply(msg, "🎛 Effects: "+describeFilters(b.filtersFor(msg.GuildID)))
+		return
+	}
+
+	var speed float64
+	if name == "speed" {
+		var err error
+		speed, err = strconv.ParseFloat(value, 64)
+		if err != nil || speed < 0.5 || speed > 2 {
+			b.reply(msg, "⚠ Please give a speed between 0.5 and 2, e.g. `!filter speed 1.25`.")
+			return
+		}
+	}
+
+	var result string
+	err := b.updateGuildSettings(msg.GuildID, func(settings *storage.GuildSettings) {
+		switch name {
+		case "clear":
+			settings.Filters = nil
+			settings.Speed = 0
+			result = "🎛 Turned off all effects."
+		case "speed":
+			settings.Speed = speed
+			if speed == 1 {
+				settings.Speed = 0
+			}
+			result = fmt.Sprintf("🎛 Speed set to %gx.", speed)
+		default:
+			on := !slices.Contains(settings.Filters, name)
+			switch value {
+			case "on":
+				on = true
+			case "off":
+				on = false
+			}
+			settings.Filters = slices.DeleteFunc(settings.Filters, func(f string) bool { return f == name })
+			if on {
+				settings.Filters = append(settings.Filters, name)
+				result = fmt.Sprintf("🎛 Turned on %s.", name)
+			} else {
+				result = fmt.Sprintf("🎛 Turned off %s.", name)
+			}
+		}
+	})
+	if err != nil {
+		log.Printf("Error saving filters for guild %s: %v", msg.GuildID, err)
+		b.reply(msg, "⚠ Couldn't save the effects, please try again.")
+		return
+	}
+	b.reply(msg, result+" Effects change from the next song.")
+}
+
+func (fc FilterCommand) Help() string {
+	return "Turns audio effects on or off, starting with the next song."
+}
+
+func (fc FilterCommand) Args() []args.Spec {
+	return []args.Spec{
+		{Name: "effect", Description: "Effect to change", Kind: args.Enum,
+			Choices: []string{filterBassBoost, filterNightcore, filterNormalize, "speed", "clear"}},
+		{Name: "value", Description: "on or off, or the speed for speed", Kind: args.String},
+	}
+}
+
+func (fc FilterCommand) Category() string { return "Music" }
+
+func (fc FilterCommand) Examples() []string {
+	return []string{"!filter", "!filter bassboost", "!filter nightcore off", "!filter speed 1.25", "!filter clear"}
+}
+
+// filtersFor returns the audio effects a guild has turned on.
+func (b *BotController) filtersFor(guildID string) audio.Filters {
+	settings := b.guildSettings(guildID)
+	return audio.Filters{
+		BassBoost: slices.Contains(settings.Filters, filterBassBoost),
+		Nightcore: slices.Contains(settings.Filters, filterNightcore),
+		Normalize: slices.Contains(settings.Filters, filterNormalize),
+		Speed:     settings.Speed,
+	}
+}
+
+func describeFilters(f audio.Filters) string {
+	var on []string
+	if f.BassBoost {
+		on = append(on, filterBassBoost)
+	}
+	if f.Nightcore {
+		on = append(on, filterNightcore)
+	}
+	if f.Normalize {
+		on = append(on, filterNormalize)
+	}
+	if f.Speed > 0 && f.Speed != 1 {
+		on = append(on, fmt.Sprintf("speed %gx", f.Speed))
+	}
+	if len(on) == 0 {
+		return "none"
+	}
+	return strings.Join(on, ", ")
+}
@@ -0,0 +1,189 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AjStraight619/discord-bot/internal/args"
+	"github.com/AjStraight619/discord-bot/internal/audio"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// localPrefix marks a !play query as a path in the music library.
+	localPrefix = "local:"
+	// probeTimeout bounds ffprobe checking an upload or library file.
+	probeTimeout = 20 * time.Second
+	// libraryListMax is how many files !library lists.
+	libraryListMax = 20
+)
+
+// libraryExtensions are the files !library lists. Anything ffprobe finds audio
+// in can still be played by path.
+var libraryExtensions = []string{".mp3", ".flac", ".ogg", ".opus", ".wav", ".m4a", ".aac", ".wma"}
+
+// attachmentSongs turns the audio files attached to a message into songs,
+// telling the requester about files that are too big or aren't audio.
+func (b *BotController) attachmentSongs(msg *discordgo.MessageCreate) []*Song {
+	var songs []*Song
+	for _, att := range msg.Attachments {
+		if b.MaxFileBytes > 0 && int64(att.Size) > b.MaxFileBytes {
+			b.reply(msg, fmt.Sprintf("⚠ **%s** is too big, files can be up to %d MB.", att.Filename, b.MaxFileBytes>>20))
+			continue
+		}
+		song, err := probeSong(att.URL, strings.TrimSuffix(att.Filename, filepath.Ext(att.Filename)), msg.Author.ID)
+		if err != nil {
+			log.Printf("❌ Error probing attachment %s: %v", att.Filename, err)
+			b.reply(msg, fmt.Sprintf("⚠ **%s** isn't an audio file I can play.", att.Filename))
+			continue
+		}
+		song.URL = att.URL
+		song.upload = true
+		songs = append(songs, song)
+	}
+	return songs
+}
+
+// librarySong finds a file in the music library by its path relative to the
+// library, replying and returning nil when it can't be played.
+func (b *BotController) librarySong(msg *discordgo.MessageCreate, name string) *Song {
+	if b.MusicLibrary == "" {
+		b.reply(msg, "⚠ There's no local music library set up.")
+		return nil
+	}
+	path, err := b.libraryPath(name)
+	if err != nil {
+		b.reply(msg, fmt.Sprintf("⚠ %s.", err))
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		b.reply(msg, fmt.Sprintf("⚠ There's no file called **%s** in the library. See `!library`.", name))
+		return nil
+	}
+	if b.MaxFileBytes > 0 && info.Size() > b.MaxFileBytes {
+		b.reply(msg, fmt.Sprintf("⚠ **%s** is too big, files can be up to %d MB.", name, b.MaxFileBytes>>20))
+		return nil
+	}
+
+	song, err := probeSong(path, strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), msg.Author.ID)
+	if err != nil {
+		log.Printf("❌ Error probing %s: %v", path, err)
+		b.reply(msg, fmt.Sprintf("⚠ **%s** isn't an audio file I can play.", name))
+		return nil
+	}
+	return song
+}
+
+// libraryPath resolves a name relative to the music library, refusing paths
+// that lead outside of it, including through symlinks.
+func (b *BotController) libraryPath(name string) (string, error) {
+	root, err := filepath.Abs(b.MusicLibrary)
+	if err == nil {
+		root, err = filepath.EvalSymlinks(root)
+	}
+	if err != nil {
+		return "", err
+	}
+	path, err := filepath.EvalSymlinks(filepath.Join(root, filepath.FromSlash(name)))
+	if err != nil {
+		return "", fmt.Errorf("there's no file called **%s** in the library. See `!library`", name)
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("that path isn't in the music library")
+	}
+	return path, nil
+}
+
+// probeSong checks that target holds audio with ffprobe and describes it as a
+// song, using its tags for the title when it has them.
+func probeSong(target, title, requester string) (*Song, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	info, err := audio.Probe(ctx, binPath("ffprobe.exe"), target)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("🔍 Detected %s audio (%s) in %s", info.Codec, info.Format, title)
+
+	song := &Song{
+		File:        target,
+		Title:       title,
+		Uploader:    info.Artist,
+		Duration:    info.Duration,
+		RequestedBy: requester,
+	}
+	if info.Title != "" {
+		song.Title = info.Title
+	}
+	return song, nil
+}
+
+// LibraryCommand lists the files in the local music library.
+type LibraryCommand struct{}
+
+func (lc LibraryCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	if b.MusicLibrary == "" {
+		b.reply(msg, "⚠ There's no local music library set up.")
+		return
+	}
+
+	search := strings.ToLower(opts.String("search"))
+	var matches []string
+	total := 0
+	err := filepath.WalkDir(b.MusicLibrary, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !slices.Contains(libraryExtensions, strings.ToLower(filepath.Ext(path))) {
+			return err
+		}
+		rel, err := filepath.Rel(b.MusicLibrary, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if search != "" && !strings.Contains(strings.ToLower(rel), search) {
+			return nil
+		}
+		total++
+		if len(matches) < libraryListMax {
+			matches = append(matches, "• `"+rel+"`")
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error listing music library: %v", err)
+		b.reply(msg, "⚠ Couldn't read the music library.")
+		return
+	}
+
+	if total == 0 {
+		b.reply(msg, "📁 No matching files in the music library.")
+		return
+	}
+	text := fmt.Sprintf("📁 **Music library** (%d file(s))\n%s", total, strings.Join(matches, "\n"))
+	if total > len(matches) {
+		text += fmt.Sprintf("\n…and %d more, narrow it down with `!library <search>`.", total-len(matches))
+	}
+	b.reply(msg, text+"\nPlay one with `!play local:<path>`.")
+}
+
+func (lc LibraryCommand) Help() string { return "Lists the songs in the local music library." }
+
+func (lc LibraryCommand) Args() []args.Spec {
+	return []args.Spec{
+		{Name: "search", Description: "Only list files whose path contains this", Kind: args.Text},
+	}
+}
+
+func (lc LibraryCommand) Category() string { return "Music" }
+
+func (lc LibraryCommand) Examples() []string { return []string{"!library", "!library beatles"} }
@@ -0,0 +1,70 @@
+package bot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLibraryPath(t *testing.T) {
+	dir := t.TempDir()
+	library := filepath.Join(dir, "library")
+	for _, name := range []string{"song.mp3", "..intro.mp3", "albums/track.flac"} {
+		path := filepath.Join(library, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "secret.mp3"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	links := map[string]string{
+		"escape.mp3": filepath.Join(dir, "secret.mp3"),
+		"outside":    dir,
+		"alias.mp3":  filepath.Join(library, "song.mp3"),
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(library, name)); err != nil {
+			t.Skipf("can't create symlinks: %v", err)
+		}
+	}
+
+	b := &BotController{MusicLibrary: library}
+	tests := []struct {
+		name string
+		want string // Path relative to the library, empty when refused.
+	}{
+		{"song.mp3", "song.mp3"},
+		{"..intro.mp3", "..intro.mp3"},
+		{"albums/track.flac", "albums/track.flac"},
+		{"albums/../song.mp3", "song.mp3"},
+		{"alias.mp3", "song.mp3"},
+		{"../secret.mp3", ""},
+		{"albums/../../secret.mp3", ""},
+		{"..", ""},
+		{".", ""},
+		{"", ""},
+		{"escape.mp3", ""},
+		{"outside/secret.mp3", ""},
+		{"missing.mp3", ""},
+	}
+	root, err := filepath.EvalSymlinks(library)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		path, err := b.libraryPath(tt.name)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("libraryPath(%q) = %q, want it refused", tt.name, path)
+			}
+			continue
+		}
+		if want := filepath.Join(root, filepath.FromSlash(tt.want)); err != nil || path != want {
+			t.Errorf("libraryPath(%q) = %q, %v; want %q", tt.name, path, err, want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AjStraight619/discord-bot/internal/args"
+	"github.com/bwmarrin/discordgo"
+)
+
+// DocumentedCommand is implemented by commands that want to be grouped and
+// illustrated in !help. Commands without it are listed under "General".
+type DocumentedCommand interface {
+	Category() string
+	Examples() []string
+}
+
+// helpPageSize is the number of commands listed on each page of !help.
+const helpPageSize = 8
+
+const helpColor = 0x5865F2
+
+// HelpCommand lists the commands the caller can run, or details for one.
+type HelpCommand struct{}
+
+func (hc HelpCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	if name := opts.String("command"); name != "" {
+		b.replyComplex(msg, b.helpDetail(name, msg.GuildID, msg.ChannelID, msg.Author.ID))
+		return
+	}
+	b.replyComplex(msg, b.helpPage(0, msg.GuildID, msg.ChannelID, msg.Author.ID))
+}
+
+func (hc HelpCommand) Help() string {
+	return "Lists available commands, or shows details for one command."
+}
+
+func (hc HelpCommand) Args() []args.Spec {
+	return []args.Spec{
+		{Name: "command", Description: "Command to show details for, e.g. play", Kind: args.String},
+	}
+}
+
+func (hc HelpCommand) Category() string { return "General" }
+
+func (hc HelpCommand) Examples() []string {
+	return []string{"!help", "!help play"}
+}
+
+type helpEntry struct {
+	name     string
+	cmd      Command
+	category string
+}
+
+// helpEntries returns the commands a user can run, sorted by category then name.
+func (b *BotController) helpEntries(guildID, channelID, userID string) []helpEntry {
+	var entries []helpEntry
+	for _, name := range b.CommandRegistry.Names() {
+		cmd, _ := b.CommandRegistry.Get(name)
+		if !b.canRun(guildID, channelID, userID, name, cmd) {
+			continue
+		}
+		entries = append(entries, helpEntry{name: name, cmd: cmd, category: commandCategory(cmd)})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].category < entries[j].category
+	})
+	return entries
+}
+
+func commandCategory(cmd Command) string {
+	if dc, ok := cmd.(DocumentedCommand); ok && dc.Category() != "" {
+		return dc.Category()
+	}
+	return "General"
+}
+
+// helpPage renders one page of the command overview with buttons to flip pages.
+func (b *BotController) helpPage(page int, guildID, channelID, userID string) *discordgo.MessageSend {
+	entries := b.helpEntries(guildID, channelID, userID)
+	pages := (len(entries) + helpPageSize - 1) / helpPageSize
+	page = max(0, min(page, pages-1))
+
+	embed := &discordgo.MessageEmbed{
+		Title:  "📖 Commands",
+		Color:  helpColor,
+		Footer: &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Page %d/%d • !help <command> for details", page+1, max(pages, 1))},
+	}
+
+	start := page * helpPageSize
+	end := min(start+helpPageSize, len(entries))
+	for _, entry := range entries[start:end] {
+		line := fmt.Sprintf("`%s` — %s", entry.name, entry.cmd.Help())
+		if n := len(embed.Fields); n > 0 && embed.Fields[n-1].Name == entry.category {
+			embed.Fields[n-1].Value += "\n" + line
+			continue
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: entry.category, Value: line})
+	}
+
+	data := &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}}
+	data.Components = pagerButtons("help", page, pages)
+	return data
+}
+
+// helpDetail renders the usage, arguments and examples for a single command.
+func (b *BotController) helpDetail(name, guildID, channelID, userID string) *discordgo.MessageSend {
+	name = commandName(name)
+	cmd, ok := b.CommandRegistry.Get(name)
+	if !ok || !b.canRun(guildID, channelID, userID, name, cmd) {
+		return &discordgo.MessageSend{Content: fmt.Sprintf("Unknown command: %s", name)}
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       name,
+		Description: cmd.Help(),
+		Color:       helpColor,
+		Footer:      &discordgo.MessageEmbedFooter{Text: commandCategory(cmd)},
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Usage", Value: fmt.Sprintf("`%s`", args.Usage(name, cmd.Args()))},
+		},
+	}
+
+	if specs := cmd.Args(); len(specs) > 0 {
+		var lines []string
+		for _, spec := range specs {
+			detail := spec.Kind.String()
+			if !spec.Required {
+				detail += ", optional"
+			}
+			line := fmt.Sprintf("`%s` (%s) — %s", spec.Name, detail, spec.Description)
+			if len(spec.Choices) > 0 {
+				line += fmt.Sprintf(" [%s]", strings.Join(spec.Choices, ", "))
+			}
+			lines = append(lines, line)
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Arguments", Value: strings.Join(lines, "\n")})
+	}
+
+	if dc, ok := cmd.(DocumentedCommand); ok && len(dc.Examples()) > 0 {
+		var examples []string
+		for _, example := range dc.Examples() {
+			examples = append(examples, fmt.Sprintf("`%s`", example))
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Examples", Value: strings.Join(examples, "\n")})
+	}
+
+	return &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}}
+}
+
+// handleHelpPage flips the help message to the page in the button's payload.
+func (b *BotController) handleHelpPage(s *discordgo.Session, i *discordgo.InteractionCreate, payload string) {
+	page, err := strconv.Atoi(payload)
+	if err != nil {
+		log.Printf("Invalid help page %q: %v", payload, err)
+		return
+	}
+
+	data := b.helpPage(page, i.GuildID, i.ChannelID, interactionUser(i).ID)
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     data.Embeds,
+			Components: data.Components,
+		},
+	})
+	if err != nil {
+		log.Printf("Error updating help page: %v", err)
+	}
+}
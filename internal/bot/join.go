@@ -1,15 +1,24 @@
 package bot
 
 import (
+	"github.com/AjStraight619/discord-bot/internal/args"
 	"github.com/bwmarrin/discordgo"
 )
 
 type JoinCommand struct{}
 
-func (jc JoinCommand) Execute(b *BotController, msg *discordgo.MessageCreate, options []string) {
+func (jc JoinCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
 	b.joinUserChannel(msg.GuildID, msg.Author.ID, true, true)
 }
 
 func (jc JoinCommand) Help() string {
-	return "!join - Join the voice channel of the user who sent the command."
+	return "Join the voice channel of the user who sent the command."
+}
+
+func (jc JoinCommand) Args() []args.Spec { return nil }
+
+func (jc JoinCommand) Category() string { return "Voice" }
+
+func (jc JoinCommand) Examples() []string {
+	return []string{"!join"}
 }
@@ -3,28 +3,90 @@ package bot
 import (
 	"log"
 
+	"github.com/AjStraight619/discord-bot/internal/args"
 	"github.com/bwmarrin/discordgo"
 )
 
+// Announcements LeaveVoiceChannel makes for each reason the bot leaves.
+const (
+	leftInactive = "💤 Left the voice channel due to inactivity."
+	leftAlone    = "👋 Left the voice channel because everyone else left."
+	// Used when someone else disconnects the bot rather than the bot leaving.
+	leftDisconnected = "👋 I was disconnected from the voice channel, so I stopped the music."
+)
+
 type LeaveCommand struct{}
 
-func (lc LeaveCommand) Execute(b *BotController, msg *discordgo.MessageCreate, options []string) {
-	b.LeaveVoiceChannel()
+func (lc LeaveCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	if !b.LeaveVoiceChannel(msg.GuildID, "") {
+		b.reply(msg, "⚠ I'm not in a voice channel.")
+		return
+	}
+	b.reply(msg, "👋 Left the voice channel.")
 }
 
 func (lc LeaveCommand) Help() string {
-	return "!leave - leave voice channel"
+	return "Leave the voice channel."
+}
+
+func (lc LeaveCommand) Args() []args.Spec { return nil }
+
+func (lc LeaveCommand) Permission() int64 { return discordgo.PermissionVoiceMoveMembers }
+
+func (lc LeaveCommand) Category() string { return "Voice" }
+
+func (lc LeaveCommand) Examples() []string {
+	return []string{"!leave"}
+}
+
+// LeaveVoiceChannel stops playback and leaves the guild's voice channel,
+// posting announcement to the guild's announcement channel unless it's empty.
+// It reports whether the bot was connected.
+func (b *BotController) LeaveVoiceChannel(guildID, announcement string) bool {
+	gs, ok := b.Sessions.Lookup(guildID)
+	if !ok {
+		return false
+	}
+
+	b.stopPlayback(gs)
+	if !b.disconnectVoice(gs) {
+		return false
+	}
+	if announcement != "" {
+		b.Session.ChannelMessageSend(b.announceChannel(gs), announcement)
+	}
+	return true
 }
 
-func (b *BotController) LeaveVoiceChannel() {
-	if b.VoiceConn != nil {
-		log.Println("👋 Leaving voice channel...")
-		if b.VoiceConn.OpusRecv != nil {
-			close(b.VoiceConn.OpusRecv)
-		}
-		b.VoiceConn.Disconnect()
-		b.VoiceConn = nil
-		b.isBotInChannel = false
-		b.Session.ChannelMessageSend(b.VoiceTextChannelID, "✅ Left the voice channel due to inactivity.")
+// stopPlayback ends the current song and clears the queue.
+func (b *BotController) stopPlayback(gs *GuildSession) *Song {
+	b.cancelDownloads(gs.queue.Songs()...)
+	gs.queue.Stop()
+	stopped := gs.player.Skip()
+	b.saveQueue(gs)
+	return stopped
+}
+
+// disconnectVoice leaves the guild's voice channel, reporting whether the bot
+// was connected. The saved queue forgets the channel unless songs are left in it.
+func (b *BotController) disconnectVoice(gs *GuildSession) bool {
+	gs.mu.Lock()
+	vc := gs.VoiceConn
+	gs.VoiceConn = nil
+	gs.isBotInChannel = false
+	if gs.aloneTimer != nil {
+		gs.aloneTimer.Stop()
+		gs.aloneTimer = nil
+	}
+	gs.mu.Unlock()
+
+	if vc == nil {
+		return false
 	}
+	b.stopReceiving(gs)
+	go b.stopTranscription(gs)
+	log.Printf("👋 Leaving voice channel in guild %s...", gs.GuildID)
+	vc.Disconnect()
+	b.saveQueue(gs)
+	return true
 }
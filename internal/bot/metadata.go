@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AjStraight619/discord-bot/internal/audio"
+	"github.com/bwmarrin/discordgo"
+)
+
+// infoTimeout bounds fetching the details of the songs in one !play.
+const infoTimeout = 30 * time.Second
+
+// lookupSongs fills in the title, uploader, duration and thumbnail of songs
+// queued by link. Songs yt-dlp can't find are reported and left out. The
+// songs mustn't be queued yet, as the queue's readers don't lock them.
+func (b *BotController) lookupSongs(msg *discordgo.MessageCreate, songs []*Song) []*Song {
+	ctx, cancel := context.WithTimeout(context.Background(), infoTimeout)
+	defer cancel()
+
+	infos := make([]audio.Result, len(songs))
+	errs := make([]error, len(songs))
+	var wg sync.WaitGroup
+	for i, song := range songs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			infos[i], errs[i] = audio.Info(ctx, binPath("yt-dlp.exe"), song.URL)
+		}()
+	}
+	wg.Wait()
+
+	var found []*Song
+	for i, song := range songs {
+		if errs[i] != nil {
+			log.Println("❌ Error fetching song details:", errs[i])
+			b.reply(msg, fmt.Sprintf("⚠ Couldn't load song: %s", song.URL))
+			continue
+		}
+		song.Title = infos[i].Title
+		song.Uploader = infos[i].Uploader
+		song.Duration = infos[i].Duration
+		song.Thumbnail = infos[i].Thumbnail
+		found = append(found, song)
+	}
+	return found
+}
+
+// withinMaxDuration drops the songs that are longer than the guild allows,
+// telling the requester which ones were left out. Songs of unknown length,
+// such as live streams, are let through.
+func (b *BotController) withinMaxDuration(msg *discordgo.MessageCreate, songs []*Song) []*Song {
+	limit := b.maxDurationFor(msg.GuildID)
+	if limit <= 0 {
+		return songs
+	}
+
+	var allowed []*Song
+	var tooLong []string
+	for _, song := range songs {
+		if song.Duration > limit {
+			tooLong = append(tooLong, song.Name())
+			continue
+		}
+		allowed = append(allowed, song)
+	}
+
+	switch {
+	case len(tooLong) == 1:
+		b.reply(msg, fmt.Sprintf("⏱ **%s** is longer than the %s limit and wasn't queued.", tooLong[0], formatDuration(limit)))
+	case len(tooLong) > 1:
+		b.reply(msg, fmt.Sprintf("⏱ Skipped %d songs longer than the %s limit: %s", len(tooLong), formatDuration(limit), strings.Join(truncateList(tooLong, 5), ", ")))
+	}
+	return allowed
+}
+
+// truncateList keeps the first n names and says how many more there were.
+func truncateList(names []string, n int) []string {
+	if len(names) <= n {
+		return names
+	}
+	return append(names[:n:n], fmt.Sprintf("and %d more", len(names)-n))
+}
+
+// nowPlayingEmbed describes a song as it starts or while it plays.
+func nowPlayingEmbed(state string, song *Song, elapsed, total time.Duration) *discordgo.MessageEmbed {
+	position := formatDuration(elapsed)
+	if total > 0 {
+		position += " / " + formatDuration(total)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Author: &discordgo.MessageEmbedAuthor{Name: state},
+		Title:  song.Name(),
+		URL:    song.URL,
+		Color:  queueColor,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Position", Value: position, Inline: true},
+		},
+	}
+	if song.Uploader != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Uploader", Value: song.Uploader, Inline: true})
+	}
+	if song.RequestedBy != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Requested by", Value: "<@" + song.RequestedBy + ">", Inline: true})
+	}
+	if song.Thumbnail != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: song.Thumbnail}
+	}
+	return embed
+}
@@ -1,261 +1,412 @@
 package bot
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/AjStraight619/discord-bot/internal/args"
+	"github.com/AjStraight619/discord-bot/internal/audio"
 	"github.com/bwmarrin/discordgo"
-	"github.com/jonas747/dca"
 )
 
-// Song represents a queued song with its download status.
+// Song represents a queued song. Songs are streamed straight from YouTube and
+// only downloaded when streaming fails.
 type Song struct {
-	URL         string // The original YouTube URL.
-	FilePath    string // The local file path once downloaded.
-	Downloading bool   // True if the song is still downloading.
-	DownloadErr error  // Holds any error that occurred during download.
+	URL         string        // The original YouTube URL or attachment link, empty for library files.
+	File        string        // Path or direct link played with ffmpeg alone, for uploads and library files.
+	Title       string        // Empty until the song's details are known.
+	Uploader    string        // Channel that uploaded the video, if known.
+	Duration    time.Duration // Zero for live streams or when unknown.
+	Thumbnail   string        // URL of the video's thumbnail, if known.
+	RequestedBy string        // ID of the user who queued the song, if known.
+
+	resumeAt time.Duration // Where to start a song that was playing before a restart.
+	upload   bool          // File is a Discord attachment link. They expire, so uploads aren't saved.
 }
 
+// positionSaveInterval is how often the position in the current song is saved.
+const positionSaveInterval = 15 * time.Second
+
 // SongCommand is the command that triggers playing songs.
 type SongCommand struct{}
 
-func (sc SongCommand) Execute(b *BotController, msg *discordgo.MessageCreate, options []string) {
-	if len(options) < 1 {
-		b.displayCmdError(msg.ChannelID, "⚠ Usage: `!play <music_link>`")
+// linksSpec recognizes a query made up only of links.
+var linksSpec = []args.Spec{{Name: "links", Kind: args.URL, Required: true, Variadic: true}}
+
+func (sc SongCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	query := opts.String("query")
+
+	// Uploaded files are queued ahead of whatever the text asks for.
+	if len(msg.Attachments) > 0 {
+		if songs := b.attachmentSongs(msg); len(songs) > 0 {
+			b.Play(songs, msg)
+		}
+	}
+	if query == "" {
+		if len(msg.Attachments) == 0 {
+			b.reply(msg, "⚠ Please give a link, a search or a file to play, e.g. `!play never gonna give you up`.")
+		}
 		return
 	}
-	// Process all URLs passed in (allows multiple songs at once)
-	b.Play(options, msg)
-}
 
-func (sc SongCommand) Help() string {
-	return "!play <music_link> - Plays the specified YouTube music link(s)."
-}
+	if name, ok := strings.CutPrefix(query, localPrefix); ok {
+		if song := b.librarySong(msg, strings.Trim(strings.TrimSpace(name), `"`)); song != nil {
+			b.Play([]*Song{song}, msg)
+		}
+		return
+	}
 
-// Play adds one or more songs to the queue and starts downloading them concurrently.
-func (b *BotController) Play(options []string, msg *discordgo.MessageCreate) {
-	// Loop over all provided URLs.
+	if search, ok := strings.CutPrefix(query, topPrefix); ok {
+		if search = strings.TrimSpace(search); search == "" {
+			b.reply(msg, "⚠ Please give something to search for, e.g. `!play top: never gonna give you up`.")
+			return
+		}
+		b.searchAndPlay(msg, search, true)
+		return
+	}
 
-	for _, youtubeURL := range options {
-		log.Println("🎥 YouTube URL Received:", youtubeURL)
+	// Several links queue several songs; anything else is searched for.
+	words, _ := args.Split(query)
+	links, err := args.Parse(linksSpec, words)
+	if err != nil {
+		b.searchAndPlay(msg, query, false)
+		return
+	}
 
-		// Create a new Song instance and mark it as downloading.
-		song := &Song{
-			URL:         youtubeURL,
-			Downloading: true,
+	var songs []*Song
+	for _, link := range links.Strings("links") {
+		if audio.IsPlaylist(link) {
+			b.playPlaylist(msg, link)
+			continue
 		}
+		songs = append(songs, &Song{URL: link, RequestedBy: msg.Author.ID})
+	}
+	if len(songs) > 0 {
+		b.Play(b.lookupSongs(msg, songs), msg)
+	}
+}
 
-		// Append the song to the queue (FIFO order is preserved).
-		b.musicQueue = append(b.musicQueue, song)
-		b.displayCmdError(msg.ChannelID, fmt.Sprintf("🎵 Added to queue: %s", youtubeURL))
+func (sc SongCommand) Help() string {
+	return "Plays YouTube links and playlists, attached files or library songs, or searches YouTube."
+}
 
-		// Spawn a goroutine to download this song concurrently.
-		go func(s *Song) {
-			filePath, err := downloadYouTubeAudio(s.URL)
-			if err != nil {
-				s.DownloadErr = err
-			} else {
-				s.FilePath = filePath
-			}
-			s.Downloading = false
-		}(song)
+func (sc SongCommand) Args() []args.Spec {
+	return []args.Spec{
+		{Name: "query", Description: "Links, local:<path>, or a search (prefix top: to skip the picker)", Kind: args.Text},
 	}
+}
 
-	// If playback is not already running, start playing the queue.
-	if !b.isPlaying {
-		go b.startPlaying(msg)
+func (sc SongCommand) Category() string { return "Music" }
+
+func (sc SongCommand) Examples() []string {
+	return []string{
+		"!play https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		"!play https://www.youtube.com/playlist?list=PLFgquLnL59alCl_2TQvOiD5Vgm1hCaGSI",
+		"!play never gonna give you up",
+		"!play top: never gonna give you up",
+		"!play local:albums/abbey-road/come-together.flac",
 	}
 }
 
-// startPlaying processes the music queue and plays songs sequentially.
-func (b *BotController) startPlaying(msg *discordgo.MessageCreate) {
-	if len(b.musicQueue) == 0 {
-		b.isPlaying = false
-		b.displayCmdError(msg.ChannelID, "🎵 Queue is empty.")
+func (sc SongCommand) Deferred() bool { return true }
+
+// Play adds one or more songs to the queue and starts playing if needed. Songs
+// longer than the guild's limit are turned away.
+func (b *BotController) Play(songs []*Song, msg *discordgo.MessageCreate) {
+	songs = b.withinMaxDuration(msg, songs)
+	if len(songs) == 0 {
 		return
 	}
+	for _, song := range songs {
+		log.Println("🎥 Song received:", song.Name())
+		b.reply(msg, fmt.Sprintf("🎵 Added to queue: %s", song.Name()))
+	}
+	b.addSongs(songs, msg)
+}
 
-	b.isPlaying = true
-
-	// Process the queue in FIFO order.
-	for len(b.musicQueue) > 0 {
-		// Dequeue the first song.
-		song := b.musicQueue[0]
-		b.musicQueue = b.musicQueue[1:]
+// addSongs queues songs without announcing each one and starts playing if
+// needed.
+func (b *BotController) addSongs(songs []*Song, msg *discordgo.MessageCreate) {
+	gs := b.Sessions.Get(msg.GuildID)
+	gs.queue.Add(songs...)
+	b.prefetch(gs)
+	b.saveQueue(gs)
 
-		b.displayCmdError(msg.ChannelID, fmt.Sprintf("🎶 Now playing: %s", song.URL))
+	// If playback is not already running, start playing the queue.
+	if gs.queue.StartPlaying() {
+		go b.startPlaying(gs, msg.Author.ID)
+	}
+}
 
-		// Wait until the song finishes downloading.
-		for song.Downloading {
-			log.Println("⏳ Waiting for download to finish for:", song.URL)
-			time.Sleep(1 * time.Second)
-		}
+// startPlaying processes the guild's music queue and plays songs sequentially,
+// joining the requester's voice channel (or the guild's last one when resuming).
+// Callers must have claimed the queue with StartPlaying.
+func (b *BotController) startPlaying(gs *GuildSession, userID string) {
+	channelID := b.announceChannel(gs)
+	defer b.saveQueue(gs)
 
-		// If there was an error during download, skip the song.
-		if song.DownloadErr != nil || song.FilePath == "" {
-			log.Println("❌ Error downloading song:", song.DownloadErr)
-			b.displayCmdError(msg.ChannelID, fmt.Sprintf("⚠ Error downloading song: %s", song.URL))
-			continue
+	skipped := false
+	for {
+		song := gs.queue.Next(skipped)
+		if song == nil {
+			break
 		}
+		b.prefetch(gs)
+		opts := b.audioOptions(gs.GuildID)
+		opts.Start, song.resumeAt = song.resumeAt, 0
+		ctx := gs.player.Load(song, opts.Start, opts.Filters.Rate())
+		skipped = false
 
-		// Ensure the file exists.
-		if _, err := os.Stat(song.FilePath); os.IsNotExist(err) {
-			log.Println("❌ Error: File does not exist!", song.FilePath)
-			b.displayCmdError(msg.ChannelID, "⚠ Error: Downloaded file not found.")
+		stream, err := b.openSong(ctx, gs, song, opts)
+		if err != nil {
+			gs.player.Unload()
+			if ctx.Err() != nil {
+				skipped = true
+				continue
+			}
+			log.Println("❌ Error loading song:", err)
+			b.displayCmdError(channelID, fmt.Sprintf("⚠ Error loading song: %s", song.Name()))
+			gs.queue.Drop()
 			continue
 		}
 
 		// Join the voice channel.
-		vc, err := b.joinUserChannel(msg.GuildID, msg.Author.ID, false, true)
+		vc, err := b.joinForPlayback(gs, userID)
 		if err != nil {
-			b.displayCmdError(msg.ChannelID, "⚠ Failed to join voice channel.")
+			log.Printf("❌ Error joining voice channel in guild %s: %v", gs.GuildID, err)
+			b.displayCmdError(channelID, "⚠ Failed to join voice channel. Use `!play` to carry on with the queue.")
+			stream.Close()
+			gs.player.Unload()
+			// Like a lost connection, the song waits at the front of the queue.
+			song.resumeAt = opts.Start
+			gs.queue.Requeue()
 			return
 		}
+		b.saveQueue(gs)
 
 		log.Println("✅ Bot joined voice channel. Starting playback...")
-		time.Sleep(2 * time.Second) // Short delay before streaming.
-
-		StreamAudio(vc, song.FilePath)
+		_, _, total, _ := gs.player.Status()
+		b.announceSong(channelID, song, opts.Start, total)
+		err = b.playSong(ctx, gs, vc, song, stream, opts)
+		_, elapsed, _, _ := gs.player.Status()
+		gs.player.Unload()
+		skipped = errors.Is(err, context.Canceled)
+		switch {
+		case skipped:
+			log.Println("⏭ Song skipped:", song.Name())
+		case errors.Is(err, errVoiceLost):
+			// Keep the song at the front of the queue so the next !play, or a
+			// restart, picks it up where it stopped.
+			log.Println("❌ Couldn't reconnect to voice:", err)
+			song.resumeAt = elapsed
+			gs.queue.Requeue()
+			b.displayCmdError(channelID, "⚠ Lost the voice connection and couldn't reconnect. Use `!play` to carry on with the queue.")
+			b.disconnectVoice(gs)
+			return
+		case err != nil:
+			log.Println("❌ Error playing song:", err)
+			b.displayCmdError(channelID, fmt.Sprintf("⚠ Error playing song: %s", song.Name()))
+			gs.queue.Drop()
+		}
 	}
 
-	b.isPlaying = false
+	log.Println("🎵 Queue finished, disconnecting...")
+	b.disconnectVoice(gs)
 }
 
-// StreamAudio streams the specified audio file to Discord.
-func StreamAudio(vc *discordgo.VoiceConnection, filename string) {
-	log.Println("🎵 Preparing to stream audio...")
+// playSong plays an opened song to the end. When the player is restarted, e.g.
+// for a volume change or a seek, the song is opened again at its new position.
+// When the voice connection drops it's reconnected and the song carries on
+// from where it was. The position is saved regularly so a restart of the bot
+// can resume it.
+func (b *BotController) playSong(ctx context.Context, gs *GuildSession, vc *discordgo.VoiceConnection, song *Song, stream *audio.Stream, opts audio.Options) error {
+	done := make(chan struct{})
+	defer close(done)
+	go b.savePosition(gs, done)
 
-	if vc == nil {
-		log.Println("❌ Voice connection is nil. Cannot play audio.")
-		return
-	}
+	for {
+		err := gs.player.Play(ctx, vc, stream)
+		if closeErr := stream.Close(); err == nil && closeErr != nil {
+			log.Println("⚠ Error closing stream:", closeErr)
+		}
+		if errors.Is(err, errVoiceLost) {
+			log.Printf("📡 Lost the voice connection in guild %s during %s", gs.GuildID, song.Name())
+			vc, err = b.reconnectVoice(ctx, gs)
+			if err != nil {
+				if ctx.Err() != nil {
+					return context.Canceled
+				}
+				return fmt.Errorf("%w: %w", errVoiceLost, err)
+			}
+			gs.player.Restart()
+		}
+		if !restarting(ctx) {
+			return err
+		}
 
-	absPath, err := filepath.Abs(filename)
-	if err != nil {
-		log.Println("❌ Error getting absolute path:", err)
-		return
-	}
+		for {
+			ctx, opts.Start = gs.player.Reload()
+			opts.Volume = b.volumeFor(gs.GuildID)
+			log.Printf("🔁 Restarting %s at %s", song.Name(), formatDuration(opts.Start))
 
-	log.Println("✅ Checking file:", absPath)
-	fileInfo, err := os.Stat(absPath)
-	if err != nil {
-		log.Println("❌ Error: Downloaded file does not exist!", absPath)
-		return
+			stream, err = b.openSong(ctx, gs, song, opts)
+			if err == nil {
+				break
+			}
+			if !restarting(ctx) {
+				if ctx.Err() != nil {
+					return context.Canceled
+				}
+				return err
+			}
+		}
 	}
+}
 
-	log.Println("📂 File Size:", fileInfo.Size(), "bytes")
-	log.Println("🔍 File Format:", filepath.Ext(absPath))
-	if fileInfo.Size() < 1000 {
-		log.Println("❌ File is too small! Probably an empty/corrupt MP3.")
-		return
+// savePosition saves the guild's queue, and so the current song's position,
+// every positionSaveInterval until done is closed.
+func (b *BotController) savePosition(gs *GuildSession, done <-chan struct{}) {
+	ticker := time.NewTicker(positionSaveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			b.saveQueue(gs)
+		case <-done:
+			return
+		}
 	}
+}
 
-	log.Println("✅ Voice connection established.")
-	vc.Speaking(true)
-	defer vc.Speaking(false)
-
-	log.Println("🔄 Encoding file with DCA:", absPath)
-	options := dca.StdEncodeOptions
-	options.RawOutput = true
-	options.Bitrate = 96
-	options.Application = "audio"
-	options.Volume = 10.0
-	options.FrameRate = 48000
-	options.BufferedFrames = 100
+// audioOptions returns how a guild's songs are encoded: its volume, the
+// effects it has turned on and the ffmpeg installed into ./bin to seek with.
+func (b *BotController) audioOptions(guildID string) audio.Options {
+	return audio.Options{Volume: b.volumeFor(guildID), Filters: b.filtersFor(guildID), FFmpeg: binPath("ffmpeg.exe")}
+}
 
-	encodeSession, err := dca.EncodeFile(absPath, options)
+// announceSong posts the "Now playing" embed for a song of length total that's
+// starting at position at.
+func (b *BotController) announceSong(channelID string, song *Song, at, total time.Duration) {
+	_, err := b.Session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{nowPlayingEmbed("🎶 Now playing", song, at, total)},
+	})
 	if err != nil {
-		log.Println("❌ Error encoding file with DCA:", err)
-		return
+		log.Println("❌ Error announcing song:", err)
 	}
-	defer encodeSession.Cleanup()
+}
 
-	log.Println("✅ Audio file encoded, starting playback...")
-	frameCount := 0
-	for {
-		frame, err := encodeSession.OpusFrame()
-		if err != nil {
-			log.Println("🎵 Finished playing, disconnecting...")
-			break
+// openSong starts encoding a song. Uploads and library files are read by ffmpeg
+// directly, cached songs are played from disk, songs that are already being
+// prefetched wait for their download, and others are streamed from YouTube,
+// falling back to downloading the song first.
+func (b *BotController) openSong(ctx context.Context, gs *GuildSession, song *Song, opts audio.Options) (*audio.Stream, error) {
+	if song.File != "" {
+		log.Println("📁 Playing file:", song.Name())
+		return audio.OpenFile(song.File, opts)
+	}
+
+	if b.AudioCache != nil {
+		if path, ok := b.AudioCache.Get(audio.CacheKey(song.URL)); ok {
+			log.Println("📦 Playing cached audio:", path)
+			return openFile(gs, song, path, opts)
 		}
-		if len(frame) == 0 {
-			log.Println("⚠ Warning: Empty audio frame, DCA might be broken.")
-			continue
+	}
+
+	if b.Downloads != nil {
+		if _, ok := b.Downloads.Lookup(song.URL); ok {
+			return b.downloadAndOpen(ctx, gs, song, opts)
 		}
-		vc.OpusSend <- frame
-		frameCount++
 	}
-	log.Println("✅ Total frames sent:", frameCount)
-	vc.Disconnect()
+
+	stream, err := audio.Open(ctx, b.audioSource(), song.URL, opts)
+	if err == nil {
+		log.Println("📡 Streaming:", song.URL)
+		return stream, nil
+	}
+	if ctx.Err() != nil || b.Downloads == nil {
+		return nil, err
+	}
+	log.Println("⚠ Streaming failed, downloading instead:", err)
+	return b.downloadAndOpen(ctx, gs, song, opts)
 }
 
-// downloadYouTubeAudio downloads and converts audio using yt-dlp and FFmpeg.
-func downloadYouTubeAudio(url string) (string, error) {
-	projectRoot, err := filepath.Abs(".")
-	if err != nil {
-		log.Println("❌ Error getting project root:", err)
-		return "", err
+// downloadAndOpen downloads a song ahead of everything else waiting for a
+// worker, then encodes the file.
+func (b *BotController) downloadAndOpen(ctx context.Context, gs *GuildSession, song *Song, opts audio.Options) (*audio.Stream, error) {
+	job := b.Downloads.Request(song.URL, priorityNow, song)
+	select {
+	case <-job.Done():
+	case <-ctx.Done():
+		b.Downloads.Cancel(song.URL, song)
+		return nil, ctx.Err()
 	}
 
-	audioDir := filepath.Join(projectRoot, "audio")
-	os.MkdirAll(audioDir, os.ModePerm)
-	binDir := filepath.Join(projectRoot, "bin")
-	ytDLPPath := filepath.Join(binDir, "yt-dlp.exe")
-	ffmpegPath := filepath.Join(binDir, "ffmpeg.exe")
-	ytdlpOutputTemplate := filepath.Join(audioDir, "%(title)s.%(ext)s")
-
-	log.Println("📥 Downloading YouTube audio with yt-dlp...")
-	cmd := exec.Command(ytDLPPath, "-f", "bestaudio", "-o", ytdlpOutputTemplate, url)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	err = cmd.Run()
+	path, err := job.Result()
 	if err != nil {
-		log.Println("❌ Error downloading YouTube audio:", err)
-		return "", err
+		return nil, err
 	}
+	return openFile(gs, song, path, opts)
+}
 
-	files, err := os.ReadDir(audioDir)
+// openFile encodes a downloaded song, telling the player its duration if it
+// wasn't known.
+func openFile(gs *GuildSession, song *Song, path string, opts audio.Options) (*audio.Stream, error) {
+	fileInfo, err := os.Stat(path)
 	if err != nil {
-		log.Println("❌ Error reading audio directory:", err)
-		return "", err
+		return nil, fmt.Errorf("downloaded file not found: %w", err)
 	}
-
-	var downloadedFile string
-	for _, file := range files {
-		ext := filepath.Ext(file.Name())
-		if ext == ".webm" || ext == ".m4a" {
-			downloadedFile = filepath.Join(audioDir, file.Name())
-			break
+	if fileInfo.Size() < 1000 {
+		return nil, fmt.Errorf("file is too small, probably an empty/corrupt download")
+	}
+	if song.Duration == 0 {
+		if duration, err := probeDuration(path); err == nil {
+			gs.player.SetDuration(duration)
 		}
 	}
+	return audio.OpenFile(path, opts)
+}
 
-	if downloadedFile == "" {
-		log.Println("❌ No valid downloaded file found in:", audioDir)
-		return "", fmt.Errorf("no valid downloaded file found")
+// audioSource returns where songs are streamed from, yt-dlp unless the
+// controller was given another source. Streams are saved to the audio cache.
+func (b *BotController) audioSource() audio.Source {
+	var src audio.Source = audio.YTDLP{Path: binPath("yt-dlp.exe")}
+	if b.AudioSource != nil {
+		src = b.AudioSource
+	}
+	if b.AudioCache != nil {
+		src = audio.Cached{Source: src, Cache: b.AudioCache, FFmpeg: binPath("ffmpeg.exe")}
 	}
-	log.Println("✅ Download complete:", downloadedFile)
-
-	finalAudioFile := downloadedFile[:len(downloadedFile)-len(filepath.Ext(downloadedFile))] + ".mp3"
-	log.Println("🎵 Converting to MP3 using FFmpeg...")
-	ffmpegCmd := exec.Command(ffmpegPath, "-y", "-i", downloadedFile, "-q:a", "0", "-map", "a", finalAudioFile)
-	ffmpegCmd.Stdout = os.Stdout
-	ffmpegCmd.Stderr = os.Stderr
-	err = ffmpegCmd.Run()
+	return src
+}
+
+// binPath returns the path of a tool installed into ./bin by the deps package.
+func binPath(name string) string {
+	projectRoot, err := filepath.Abs(".")
 	if err != nil {
-		log.Println("❌ Error converting audio with FFmpeg:", err)
-		return "", err
+		projectRoot = "."
 	}
+	return filepath.Join(projectRoot, "bin", name)
+}
 
-	if _, err := os.Stat(finalAudioFile); os.IsNotExist(err) {
-		log.Println("❌ Conversion failed. MP3 file not found:", finalAudioFile)
-		return "", fmt.Errorf("conversion failed: %s", finalAudioFile)
+// Name returns the song's title, or its URL until the title is known.
+func (s *Song) Name() string {
+	if s.Title != "" {
+		return s.Title
 	}
-	log.Println("✅ Conversion complete:", finalAudioFile)
-	os.Remove(downloadedFile)
+	return s.URL
+}
 
-	return finalAudioFile, nil
+// Link returns the song's name as a Markdown link, or in bold when it has no
+// URL to link to.
+func (s *Song) Link() string {
+	if s.URL == "" {
+		return "**" + s.Name() + "**"
+	}
+	return fmt.Sprintf("[%s](%s)", s.Name(), s.URL)
 }
@@ -29,7 +29,7 @@
 // // Execute fetches and sends the top news headlines for the provided country code.
 // func (n NewsCommand) Execute(b *BotController, msg *discordgo.MessageCreate, options []string) {
 // 	if len(options) == 0 {
-// 		b.Session.ChannelMessageSend(msg.ChannelID, "Please specify a country code. Example: `!news us`")
+// 		b.reply(msg, "Please specify a country code. Example: `!news us`")
 // 		return
 // 	}
 
@@ -88,7 +88,7 @@
 // // DisplayNewsResponse is called when `!news` is used
 // func (b *BotController) DisplayNewsResponse(options []string, msg *discordgo.MessageCreate) {
 // 	if len(options) == 0 {
-// 		b.Session.ChannelMessageSend(msg.ChannelID, "Please specify a country code. Example: `!news us`")
+// 		b.reply(msg, "Please specify a country code. Example: `!news us`")
 // 		return
 // 	}
 
@@ -110,30 +110,43 @@ import (
 	"log"
 
 	"github.com/AjStraight619/discord-bot/internal/apiclients"
+	"github.com/AjStraight619/discord-bot/internal/args"
 	"github.com/bwmarrin/discordgo"
 )
 
 type NewsCommand struct{}
 
-func (n NewsCommand) Execute(b *BotController, msg *discordgo.MessageCreate, options []string) {
-	if len(options) == 0 {
-		b.Session.ChannelMessageSend(msg.ChannelID, "Please specify a country code. Example: `!news us`")
-		return
+func (n NewsCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	country := opts.String("country")
+	if country == "" {
+		country = b.newsCountryFor(msg.GuildID)
 	}
 
-	country := options[0]
-
 	// Call the external API function directly.
 	newsMessage, err := apiclients.GetTopNews(country)
 	if err != nil {
 		log.Printf("Error getting news: %v", err)
-		b.Session.ChannelMessageSend(msg.ChannelID, "Error fetching news. Please try again.")
+		b.reply(msg, "Error fetching news. Please try again.")
 		return
 	}
 
-	b.Session.ChannelMessageSend(msg.ChannelID, newsMessage)
+	b.reply(msg, newsMessage)
 }
 
 func (n NewsCommand) Help() string {
-	return "!news <country code> - Displays the top headlines for the specified country."
+	return "Displays the top headlines for the specified country, or the server's default."
+}
+
+func (n NewsCommand) Args() []args.Spec {
+	return []args.Spec{
+		{Name: "country", Description: "Two letter country code, defaults to the server's news-country setting", Kind: args.String},
+	}
 }
+
+func (n NewsCommand) Category() string { return "Information" }
+
+func (n NewsCommand) Examples() []string {
+	return []string{"!news", "!news us", "!news gb"}
+}
+
+func (n NewsCommand) Deferred() bool { return true }
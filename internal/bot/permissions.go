@@ -0,0 +1,265 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/AjStraight619/discord-bot/internal/storage"
+	"github.com/bwmarrin/discordgo"
+)
+
+// RestrictedCommand is implemented by commands that need a Discord permission
+// to run, e.g. discordgo.PermissionManageServer.
+type RestrictedCommand interface {
+	Permission() int64
+}
+
+// CommandRule is a guild's access rule for one command. Granted roles may run
+// the command even without its Discord permission, and once any roles are
+// granted only members with one of them (or administrators) may run it. Once
+// any channels are granted the command only works in those channels.
+type CommandRule struct {
+	Roles    []string `json:"roles,omitempty"`
+	Channels []string `json:"channels,omitempty"`
+}
+
+func (r *CommandRule) empty() bool {
+	return len(r.Roles) == 0 && len(r.Channels) == 0
+}
+
+// AuditEntry records an access decision or a change to the access rules.
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	GuildID   string    `json:"guild_id"`
+	ChannelID string    `json:"channel_id"`
+	UserID    string    `json:"user_id"`
+	Command   string    `json:"command"`
+	Action    string    `json:"action"` // "denied", "grant", "revoke" or "reset".
+	Detail    string    `json:"detail"`
+}
+
+// auditLogSize is the number of audit entries kept per guild.
+const auditLogSize = 50
+
+// PermissionRules holds per-guild command access rules and the audit log. Both
+// are written through to the store when one is configured.
+type PermissionRules struct {
+	mu    sync.RWMutex
+	rules map[string]map[string]*CommandRule // Guild ID -> command name -> rule.
+	audit map[string][]AuditEntry            // Guild ID -> most recent entries.
+
+	rulesRepo *storage.Repository[map[string]*CommandRule]
+	auditRepo *storage.Repository[[]AuditEntry]
+}
+
+// NewPermissionRules loads the saved rules and audit log from store. A nil store
+// keeps everything in memory.
+func NewPermissionRules(store storage.Store) *PermissionRules {
+	pr := &PermissionRules{
+		rules: make(map[string]map[string]*CommandRule),
+		audit: make(map[string][]AuditEntry),
+	}
+	if store == nil {
+		return pr
+	}
+
+	pr.rulesRepo = storage.NewRepository[map[string]*CommandRule](store, storage.BucketPermissions)
+	pr.auditRepo = storage.NewRepository[[]AuditEntry](store, storage.BucketAudit)
+
+	rules, err := pr.rulesRepo.All()
+	if err != nil {
+		log.Printf("Error loading permission rules: %v", err)
+	}
+	for guildID, guildRules := range rules {
+		pr.rules[guildID] = guildRules
+	}
+
+	audit, err := pr.auditRepo.All()
+	if err != nil {
+		log.Printf("Error loading audit log: %v", err)
+	}
+	for guildID, entries := range audit {
+		pr.audit[guildID] = entries
+	}
+	return pr
+}
+
+// Rule returns a copy of the rule for a command in a guild.
+func (pr *PermissionRules) Rule(guildID, command string) CommandRule {
+	pr.mu.RLock()
+	defer pr.mu.RUnlock()
+
+	if rule, ok := pr.rules[guildID][command]; ok {
+		return CommandRule{Roles: slices.Clone(rule.Roles), Channels: slices.Clone(rule.Channels)}
+	}
+	return CommandRule{}
+}
+
+// Rules returns a copy of every rule configured in a guild.
+func (pr *PermissionRules) Rules(guildID string) map[string]CommandRule {
+	pr.mu.RLock()
+	defer pr.mu.RUnlock()
+
+	out := make(map[string]CommandRule, len(pr.rules[guildID]))
+	for name, rule := range pr.rules[guildID] {
+		out[name] = CommandRule{Roles: slices.Clone(rule.Roles), Channels: slices.Clone(rule.Channels)}
+	}
+	return out
+}
+
+// Update applies fn to the rule for a command, dropping the rule once it's empty.
+func (pr *PermissionRules) Update(guildID, command string, fn func(rule *CommandRule)) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	if pr.rules[guildID] == nil {
+		pr.rules[guildID] = make(map[string]*CommandRule)
+	}
+	rule, ok := pr.rules[guildID][command]
+	if !ok {
+		rule = &CommandRule{}
+		pr.rules[guildID][command] = rule
+	}
+	fn(rule)
+	if rule.empty() {
+		delete(pr.rules[guildID], command)
+	}
+
+	if pr.rulesRepo != nil {
+		if err := pr.rulesRepo.Put(guildID, pr.rules[guildID]); err != nil {
+			log.Printf("Error saving permission rules for guild %s: %v", guildID, err)
+		}
+	}
+}
+
+// Record appends an entry to the guild's audit log.
+func (pr *PermissionRules) Record(entry AuditEntry) {
+	log.Printf("AUDIT guild=%s channel=%s user=%s command=%s action=%s: %s",
+		entry.GuildID, entry.ChannelID, entry.UserID, entry.Command, entry.Action, entry.Detail)
+
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	entries := append(pr.audit[entry.GuildID], entry)
+	if len(entries) > auditLogSize {
+		entries = entries[len(entries)-auditLogSize:]
+	}
+	pr.audit[entry.GuildID] = entries
+
+	if pr.auditRepo != nil {
+		if err := pr.auditRepo.Put(entry.GuildID, entries); err != nil {
+			log.Printf("Error saving audit log for guild %s: %v", entry.GuildID, err)
+		}
+	}
+}
+
+// AuditLog returns the guild's audit entries, most recent last.
+func (pr *PermissionRules) AuditLog(guildID string) []AuditEntry {
+	pr.mu.RLock()
+	defer pr.mu.RUnlock()
+	return slices.Clone(pr.audit[guildID])
+}
+
+// checkAccess decides whether a user may run a command in a channel, returning a
+// user-facing reason when they may not.
+func (b *BotController) checkAccess(guildID, channelID, userID, name string, cmd Command) (bool, string) {
+	var required int64
+	if rc, ok := cmd.(RestrictedCommand); ok {
+		required = rc.Permission()
+	}
+
+	// Outside of a guild there are no roles or channel permissions to check.
+	if guildID == "" {
+		if required != 0 {
+			return false, fmt.Sprintf("`%s` can only be used in a server.", name)
+		}
+		return true, ""
+	}
+
+	perms, err := b.Session.UserChannelPermissions(userID, channelID)
+	if err != nil {
+		log.Printf("Error getting permissions for user %s in channel %s: %v", userID, channelID, err)
+		return false, "I couldn't check your permissions, please try again."
+	}
+	if perms&discordgo.PermissionAdministrator != 0 {
+		return true, ""
+	}
+
+	rule := b.Permissions.Rule(guildID, name)
+	if len(rule.Channels) > 0 && !slices.Contains(rule.Channels, channelID) {
+		return false, fmt.Sprintf("`%s` isn't allowed in this channel.", name)
+	}
+
+	if len(rule.Roles) > 0 {
+		if b.memberHasAnyRole(guildID, userID, rule.Roles) {
+			return true, ""
+		}
+		return false, fmt.Sprintf("You need one of the roles allowed to use `%s`.", name)
+	}
+
+	if required != 0 && perms&required != required {
+		return false, fmt.Sprintf("You need the %s permission to use `%s`.", permissionName(required), name)
+	}
+	return true, ""
+}
+
+// authorize checks access for a command invocation and records denials in the
+// audit log.
+func (b *BotController) authorize(guildID, channelID, userID, name string, cmd Command) (bool, string) {
+	ok, reason := b.checkAccess(guildID, channelID, userID, name, cmd)
+	if !ok {
+		b.Permissions.Record(AuditEntry{
+			Time:      time.Now(),
+			GuildID:   guildID,
+			ChannelID: channelID,
+			UserID:    userID,
+			Command:   name,
+			Action:    "denied",
+			Detail:    reason,
+		})
+		reason = "⛔ " + reason
+	}
+	return ok, reason
+}
+
+// canRun reports whether a user may run a command in a channel.
+func (b *BotController) canRun(guildID, channelID, userID, name string, cmd Command) bool {
+	ok, _ := b.checkAccess(guildID, channelID, userID, name, cmd)
+	return ok
+}
+
+func (b *BotController) memberHasAnyRole(guildID, userID string, roles []string) bool {
+	member, err := b.Session.State.Member(guildID, userID)
+	if err != nil {
+		member, err = b.Session.GuildMember(guildID, userID)
+		if err != nil {
+			log.Printf("Error getting member %s in guild %s: %v", userID, guildID, err)
+			return false
+		}
+	}
+	for _, role := range member.Roles {
+		if slices.Contains(roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+var permissionNames = map[int64]string{
+	discordgo.PermissionManageServer:     "Manage Server",
+	discordgo.PermissionManageChannels:   "Manage Channels",
+	discordgo.PermissionManageRoles:      "Manage Roles",
+	discordgo.PermissionManageMessages:   "Manage Messages",
+	discordgo.PermissionVoiceMoveMembers: "Move Members",
+	discordgo.PermissionAdministrator:    "Administrator",
+}
+
+func permissionName(perm int64) string {
+	if name, ok := permissionNames[perm]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%x", perm)
+}
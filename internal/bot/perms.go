@@ -0,0 +1,171 @@
+package bot
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AjStraight619/discord-bot/internal/args"
+	"github.com/bwmarrin/discordgo"
+)
+
+// PermsCommand lets server managers grant or revoke commands per role or channel.
+type PermsCommand struct{}
+
+func (pc PermsCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	action := opts.String("action")
+	if action == "audit" {
+		b.reply(msg, formatAuditLog(b.Permissions.AuditLog(msg.GuildID)))
+		return
+	}
+
+	if !opts.Has("command") {
+		if action == "show" {
+			b.reply(msg, formatRules(b.Permissions.Rules(msg.GuildID)))
+			return
+		}
+		b.reply(msg, fmt.Sprintf("⚠ Please specify a command, e.g. `!perms %s timeout`.", action))
+		return
+	}
+
+	name := commandName(opts.String("command"))
+	if _, ok := b.CommandRegistry.Get(name); !ok {
+		b.reply(msg, fmt.Sprintf("Unknown command: %s", name))
+		return
+	}
+
+	switch action {
+	case "show":
+		b.reply(msg, formatRule(name, b.Permissions.Rule(msg.GuildID, name)))
+		return
+	case "reset":
+		b.Permissions.Update(msg.GuildID, name, func(rule *CommandRule) {
+			*rule = CommandRule{}
+		})
+		b.recordRuleChange(msg, name, action, "all grants removed")
+		b.reply(msg, fmt.Sprintf("✅ Reset access rules for `%s`.", name))
+		return
+	}
+
+	kind, id, ok := args.Mention(opts.String("target"))
+	if !ok || (kind != args.Role && kind != args.Channel) {
+		b.reply(msg, fmt.Sprintf("⚠ Please mention a role or channel, e.g. `!perms %s %s @DJ`.", action, name))
+		return
+	}
+
+	b.Permissions.Update(msg.GuildID, name, func(rule *CommandRule) {
+		list := &rule.Roles
+		if kind == args.Channel {
+			list = &rule.Channels
+		}
+		*list = slices.DeleteFunc(*list, func(v string) bool { return v == id })
+		if action == "grant" {
+			*list = append(*list, id)
+		}
+	})
+
+	target := mentionOf(kind, id)
+	b.recordRuleChange(msg, name, action, target)
+	if action == "grant" {
+		b.reply(msg, fmt.Sprintf("✅ Granted `%s` to %s.", name, target))
+	} else {
+		b.reply(msg, fmt.Sprintf("✅ Revoked `%s` from %s.", name, target))
+	}
+}
+
+func (pc PermsCommand) Help() string {
+	return "Grant or revoke commands per role or channel, and review denied attempts."
+}
+
+func (pc PermsCommand) Args() []args.Spec {
+	return []args.Spec{
+		{Name: "action", Description: "What to do", Kind: args.Enum, Required: true, Choices: []string{"grant", "revoke", "reset", "show", "audit"}},
+		{Name: "command", Description: "Command to change, e.g. timeout", Kind: args.String},
+		{Name: "target", Description: "Role or channel mention", Kind: args.String},
+	}
+}
+
+func (pc PermsCommand) Permission() int64 { return discordgo.PermissionManageServer }
+
+func (pc PermsCommand) Category() string { return "Admin" }
+
+func (pc PermsCommand) Examples() []string {
+	return []string{"!perms grant timeout @DJ", "!perms grant play #music", "!perms revoke play #music", "!perms show", "!perms audit"}
+}
+
+func (b *BotController) recordRuleChange(msg *discordgo.MessageCreate, name, action, detail string) {
+	b.Permissions.Record(AuditEntry{
+		Time:      time.Now(),
+		GuildID:   msg.GuildID,
+		ChannelID: msg.ChannelID,
+		UserID:    msg.Author.ID,
+		Command:   name,
+		Action:    action,
+		Detail:    detail,
+	})
+}
+
+func mentionOf(kind args.Kind, id string) string {
+	if kind == args.Channel {
+		return "<#" + id + ">"
+	}
+	return "<@&" + id + ">"
+}
+
+func formatRule(name string, rule CommandRule) string {
+	if rule.empty() {
+		return fmt.Sprintf("`%s` uses its default permissions.", name)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**%s**\n", name)
+	if len(rule.Roles) > 0 {
+		var roles []string
+		for _, id := range rule.Roles {
+			roles = append(roles, mentionOf(args.Role, id))
+		}
+		fmt.Fprintf(&sb, "Roles: %s\n", strings.Join(roles, ", "))
+	}
+	if len(rule.Channels) > 0 {
+		var channels []string
+		for _, id := range rule.Channels {
+			channels = append(channels, mentionOf(args.Channel, id))
+		}
+		fmt.Fprintf(&sb, "Channels: %s\n", strings.Join(channels, ", "))
+	}
+	return sb.String()
+}
+
+func formatRules(rules map[string]CommandRule) string {
+	if len(rules) == 0 {
+		return "No access rules configured; every command uses its default permissions."
+	}
+
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var parts []string
+	for _, name := range names {
+		parts = append(parts, formatRule(name, rules[name]))
+	}
+	return strings.Join(parts, "\n")
+}
+
+// formatAuditLog shows the most recent audit entries, newest first.
+func formatAuditLog(entries []AuditEntry) string {
+	if len(entries) == 0 {
+		return "The audit log is empty."
+	}
+
+	var sb strings.Builder
+	sb.WriteString("📜 **Recent access events**\n")
+	for i := len(entries) - 1; i >= 0 && i >= len(entries)-10; i-- {
+		e := entries[i]
+		fmt.Fprintf(&sb, "<t:%d:R> <@%s> %s `%s` in <#%s>: %s\n", e.Time.Unix(), e.UserID, e.Action, e.Command, e.ChannelID, e.Detail)
+	}
+	return sb.String()
+}
@@ -0,0 +1,301 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// frameDuration is the length of audio in each Opus frame the encoder produces.
+const frameDuration = 20 * time.Millisecond
+
+// voiceSendTimeout is how long a frame may wait to be sent before the voice
+// connection is considered lost.
+const voiceSendTimeout = 5 * time.Second
+
+// errVoiceLost is returned by Play when Discord stops taking audio, which
+// happens while the voice connection is down.
+var errVoiceLost = errors.New("voice connection lost")
+
+// errRestart is the cancellation cause of a song whose encoder is being
+// restarted, e.g. to apply a new volume.
+var errRestart = errors.New("restarting playback")
+
+// Player streams one song at a time to a voice connection and lets commands
+// skip, pause or resume it while it plays.
+type Player struct {
+	mu      sync.Mutex
+	song    *Song
+	cancel  context.CancelCauseFunc
+	paused  bool
+	resumed chan struct{} // Closed when a paused player resumes.
+	frames  int           // Frames sent since the song (re)started.
+	offset  time.Duration // Position in the song the encoder started at.
+	rate    float64       // Seconds of song per second of playback.
+	total   time.Duration // Length of the current song, zero if unknown.
+	started bool          // Play has begun sending the current song.
+	skipped bool          // Skip was called, so a pending restart is void.
+	seeking bool          // The pending restart jumps to seekTo.
+	seekTo  time.Duration
+}
+
+// Load makes song the current song, starting offset into it and played rate
+// times faster than normal. The returned context is cancelled when the song is
+// skipped, so callers can stop waiting on it before playback starts.
+func (p *Player) Load(song *Song, offset time.Duration, rate float64) context.Context {
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.song = song
+	p.cancel = cancel
+	p.paused = false
+	p.resumed = nil
+	p.frames = 0
+	p.offset = offset
+	p.rate = rate
+	p.total = song.Duration
+	p.started = false
+	p.skipped = false
+	p.seeking = false
+	return ctx
+}
+
+// SetDuration sets the length of the current song once it's found out, e.g.
+// from a downloaded file. Queued songs are read without a lock, so they
+// aren't changed instead.
+func (p *Player) SetDuration(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total = d
+}
+
+// Restart stops the encoder of the current song so it can be started again
+// from the current position. It reports false when no song has started
+// playing, in which case changes apply once it does anyway.
+func (p *Player) Restart() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.song == nil || !p.started {
+		return false
+	}
+	p.cancel(errRestart)
+	return true
+}
+
+// Seek restarts the current song at position to. It reports false when no
+// song has started playing.
+func (p *Player) Seek(to time.Duration) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.song == nil || !p.started {
+		return false
+	}
+	p.seeking = true
+	p.seekTo = max(to, 0)
+	p.cancel(errRestart)
+	return true
+}
+
+// Reload continues the current song after a restart, from the position it got
+// to or the one it was seeked to. It returns the context for the new encoder
+// and the position to start it at. Pausing carries over, and if the song was
+// skipped in the meantime the context is already cancelled.
+func (p *Player) Reload() (context.Context, time.Duration) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	offset := p.elapsed()
+	if p.seeking {
+		offset = p.seekTo
+		p.seeking = false
+	}
+	p.cancel = cancel
+	p.frames = 0
+	p.offset = offset
+	if p.skipped {
+		cancel(nil)
+	}
+	return ctx, offset
+}
+
+// restarting reports whether ctx, from Load or Reload, was cancelled by Restart.
+func restarting(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), errRestart)
+}
+
+// Unload clears the current song once it has finished or been skipped.
+func (p *Player) Unload() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel != nil {
+		p.cancel(nil)
+	}
+	p.song = nil
+	p.cancel = nil
+	p.paused = false
+	p.resumed = nil
+}
+
+// Skip cancels the current song and returns it, or nil when nothing is loaded.
+func (p *Player) Skip() *Song {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.song != nil {
+		p.cancel(nil)
+		p.skipped = true
+	}
+	return p.song
+}
+
+// Pause holds playback after the current frame. It reports false when nothing
+// is loaded or the player is already paused.
+func (p *Player) Pause() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.song == nil || p.paused {
+		return false
+	}
+	p.paused = true
+	p.resumed = make(chan struct{})
+	return true
+}
+
+// Resume continues a paused song. It reports false when the player isn't paused.
+func (p *Player) Resume() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.paused {
+		return false
+	}
+	p.paused = false
+	close(p.resumed)
+	return true
+}
+
+// Status returns the current song, how far into it playback is, its length
+// (zero if unknown) and whether it's paused.
+func (p *Player) Status() (song *Song, elapsed, total time.Duration, paused bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.song, p.elapsed(), p.total, p.paused
+}
+
+// elapsed returns the position in the current song. Callers hold p.mu.
+func (p *Player) elapsed() time.Duration {
+	return p.offset + time.Duration(float64(time.Duration(p.frames)*frameDuration)*p.rate)
+}
+
+// gate blocks while the player is paused. It returns false if ctx is cancelled
+// while waiting.
+func (p *Player) gate(ctx context.Context, vc *discordgo.VoiceConnection) bool {
+	p.mu.Lock()
+	paused, resumed := p.paused, p.resumed
+	p.mu.Unlock()
+	if !paused {
+		return true
+	}
+
+	vc.Speaking(false)
+	defer vc.Speaking(true)
+	select {
+	case <-resumed:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// OpusReader produces the Opus frames of a song, returning io.EOF at its end.
+type OpusReader interface {
+	OpusFrame() ([]byte, error)
+}
+
+// Play sends a song's frames to Discord until it ends or ctx is cancelled. It
+// returns errVoiceLost when a frame can't be sent for voiceSendTimeout.
+func (p *Player) Play(ctx context.Context, vc *discordgo.VoiceConnection, stream OpusReader) error {
+	if vc == nil {
+		return fmt.Errorf("voice connection is nil")
+	}
+
+	p.mu.Lock()
+	p.started = true
+	p.mu.Unlock()
+
+	vc.Speaking(true)
+	defer vc.Speaking(false)
+
+	timeout := time.NewTimer(voiceSendTimeout)
+	defer timeout.Stop()
+
+	log.Println("✅ Starting playback...")
+	for {
+		if !p.gate(ctx, vc) {
+			return ctx.Err()
+		}
+
+		frame, err := stream.OpusFrame()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("reading opus frame: %w", err)
+		}
+
+		if !timeout.Stop() {
+			select {
+			case <-timeout.C:
+			default:
+			}
+		}
+		timeout.Reset(voiceSendTimeout)
+		select {
+		case vc.OpusSend <- frame:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout.C:
+			return errVoiceLost
+		}
+
+		p.mu.Lock()
+		p.frames++
+		p.mu.Unlock()
+	}
+
+	_, elapsed, _, _ := p.Status()
+	log.Println("🎵 Finished playing after", elapsed)
+	return nil
+}
+
+// probeDuration asks ffprobe for the length of an audio file.
+func probeDuration(path string) (time.Duration, error) {
+	out, err := exec.Command(binPath("ffprobe.exe"), "-v", "error", "-show_entries", "format=duration", "-of", "csv=p=0", path).Output()
+	if err != nil {
+		return 0, err
+	}
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// formatDuration renders d as m:ss, or h:mm:ss for long tracks.
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	h, m, s := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
@@ -0,0 +1,71 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/AjStraight619/discord-bot/internal/audio"
+	"github.com/bwmarrin/discordgo"
+)
+
+// defaultPlaylistMax is how many songs of a playlist are queued unless the
+// guild's playlist-max setting says otherwise.
+const defaultPlaylistMax = 100
+
+// playlistTimeout bounds listing a playlist's contents.
+const playlistTimeout = time.Minute
+
+// playPlaylist queues the videos of a playlist, up to the guild's limit. Only
+// the playlist's listing is fetched here; each song is streamed when its turn
+// comes.
+func (b *BotController) playPlaylist(msg *discordgo.MessageCreate, url string) {
+	ctx, cancel := context.WithTimeout(context.Background(), playlistTimeout)
+	defer cancel()
+
+	limit := b.playlistMaxFor(msg.GuildID)
+	log.Println("📜 Expanding playlist:", url)
+	results, err := audio.Playlist(ctx, binPath("yt-dlp.exe"), url, limit)
+	if err != nil {
+		log.Println("❌ Error listing playlist:", err)
+		b.reply(msg, fmt.Sprintf("⚠ Couldn't load playlist: %s", url))
+		return
+	}
+	if len(results) == 0 {
+		b.reply(msg, fmt.Sprintf("⚠ Playlist is empty: %s", url))
+		return
+	}
+
+	songs := make([]*Song, len(results))
+	for i, r := range results {
+		songs[i] = songFromResult(r, msg.Author.ID)
+	}
+	songs = b.withinMaxDuration(msg, songs)
+	if len(songs) == 0 {
+		return
+	}
+	var total time.Duration
+	for _, song := range songs {
+		total += song.Duration
+	}
+	b.addSongs(songs, msg)
+
+	name := results[0].Playlist
+	if name == "" {
+		name = "playlist"
+	}
+	summary := fmt.Sprintf("📜 Added %d songs from **%s** `[%s]`", len(songs), name, formatDuration(total))
+	if len(results) == limit {
+		summary += fmt.Sprintf(" (limited to the first %d)", limit)
+	}
+	b.reply(msg, summary+".")
+}
+
+// playlistMaxFor returns how many songs of a playlist a guild queues at once.
+func (b *BotController) playlistMaxFor(guildID string) int {
+	if n := b.guildSettings(guildID).PlaylistMax; n > 0 {
+		return n
+	}
+	return defaultPlaylistMax
+}
@@ -0,0 +1,412 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AjStraight619/discord-bot/internal/args"
+	"github.com/AjStraight619/discord-bot/internal/audio"
+	"github.com/AjStraight619/discord-bot/internal/storage"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// sharedPlaylistPrefix marks a playlist name as belonging to the server
+	// rather than the user, e.g. "server:gamenight".
+	sharedPlaylistPrefix = "server:"
+	// savedPlaylistMax is the most songs a saved playlist can hold.
+	savedPlaylistMax = 500
+	// playlistShowLines is how many songs !playlist show lists.
+	playlistShowLines = 20
+)
+
+// PlaylistCommand manages named playlists that are saved for replaying later.
+// Playlists are personal unless their name starts with "server:", in which
+// case the whole server shares them.
+type PlaylistCommand struct{}
+
+func (pc PlaylistCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	if b.Store == nil {
+		b.reply(msg, "⚠ Playlists aren't available because no database is configured.")
+		return
+	}
+
+	action := opts.String("action")
+	if action == "list" {
+		b.reply(msg, b.formatPlaylists(msg))
+		return
+	}
+
+	name := opts.String("name")
+	if name == "" {
+		b.reply(msg, fmt.Sprintf("⚠ Please name the playlist, e.g. `!playlist %s gamenight`.", action))
+		return
+	}
+	key, display, err := playlistKey(msg, name)
+	if err != nil {
+		b.reply(msg, fmt.Sprintf("⚠ %s.", err))
+		return
+	}
+	playlists := storage.NewRepository[storage.SavedPlaylist](b.Store, storage.BucketPlaylists)
+
+	playlist, err := playlists.Get(key)
+	exists := err == nil
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Printf("Error loading playlist %s: %v", key, err)
+		b.reply(msg, "⚠ Couldn't load the playlist, please try again.")
+		return
+	}
+
+	switch action {
+	case "create", "save":
+		if exists && action == "create" {
+			b.reply(msg, fmt.Sprintf("⚠ **%s** already exists. Use `!playlist add` to add songs to it.", display))
+			return
+		}
+		if exists && !b.canEditPlaylist(msg, playlist) {
+			b.reply(msg, fmt.Sprintf("⛔ Only the person who created **%s** or a server manager can change it.", display))
+			return
+		}
+		owner := msg.Author.ID
+		if exists {
+			// A server manager replacing a shared playlist doesn't take it over.
+			owner = playlist.OwnerID
+		}
+		playlist = storage.SavedPlaylist{Name: display, OwnerID: owner}
+		left := ""
+		if action == "save" {
+			songs, uploads := b.queuedSongs(b.Sessions.Get(msg.GuildID))
+			if len(songs) == 0 && uploads > 0 {
+				b.reply(msg, "⚠ Uploaded files can't be saved in playlists, their links expire.")
+				return
+			}
+			if len(songs) == 0 {
+				b.reply(msg, "⚠ The queue is empty, there's nothing to save.")
+				return
+			}
+			if uploads > 0 {
+				left = fmt.Sprintf(" Left out %d uploaded file(s), their links expire.", uploads)
+			}
+			playlist.Songs = songs[:min(len(songs), savedPlaylistMax)]
+		} else if query := opts.String("songs"); query != "" {
+			for _, song := range b.resolveSongs(msg, query) {
+				playlist.Songs = append(playlist.Songs, queuedSong(song))
+			}
+			playlist.Songs = playlist.Songs[:min(len(playlist.Songs), savedPlaylistMax)]
+		}
+		if !b.savePlaylist(msg, key, playlist) {
+			return
+		}
+		b.reply(msg, fmt.Sprintf("✅ Saved **%s** with %d song(s).%s", display, len(playlist.Songs), left))
+		return
+	}
+
+	if !exists {
+		b.reply(msg, fmt.Sprintf("⚠ There's no playlist called **%s**. See `!playlist list`.", display))
+		return
+	}
+
+	switch action {
+	case "show":
+		b.replyComplex(msg, &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{playlistEmbed(playlist)}})
+
+	case "play":
+		songs := make([]*Song, len(playlist.Songs))
+		for i, saved := range playlist.Songs {
+			songs[i] = songFromQueued(saved)
+			songs[i].RequestedBy = msg.Author.ID
+		}
+		songs = b.withinMaxDuration(msg, songs)
+		if len(songs) == 0 {
+			return
+		}
+		b.addSongs(songs, msg)
+		b.reply(msg, fmt.Sprintf("📜 Added %d songs from **%s**.", len(songs), display))
+
+	case "add":
+		if !b.canEditPlaylist(msg, playlist) {
+			b.reply(msg, fmt.Sprintf("⛔ Only the person who created **%s** or a server manager can change it.", display))
+			return
+		}
+		query := opts.String("songs")
+		if query == "" {
+			b.reply(msg, fmt.Sprintf("⚠ Please give links or a search, e.g. `!playlist add %s never gonna give you up`.", name))
+			return
+		}
+		songs := b.resolveSongs(msg, query)
+		if len(songs) == 0 {
+			return
+		}
+		room := savedPlaylistMax - len(playlist.Songs)
+		if len(songs) > room {
+			b.reply(msg, fmt.Sprintf("⚠ Playlists can hold at most %d songs, only adding %d.", savedPlaylistMax, max(room, 0)))
+			songs = songs[:max(room, 0)]
+		}
+		for _, song := range songs {
+			playlist.Songs = append(playlist.Songs, queuedSong(song))
+		}
+		if !b.savePlaylist(msg, key, playlist) {
+			return
+		}
+		if len(songs) == 1 {
+			b.reply(msg, fmt.Sprintf("➕ Added %s to **%s**.", songs[0].Name(), display))
+		} else {
+			b.reply(msg, fmt.Sprintf("➕ Added %d songs to **%s**.", len(songs), display))
+		}
+
+	case "remove":
+		if !b.canEditPlaylist(msg, playlist) {
+			b.reply(msg, fmt.Sprintf("⛔ Only the person who created **%s** or a server manager can change it.", display))
+			return
+		}
+		words, _ := args.Split(opts.String("songs"))
+		values, err := args.Parse([]args.Spec{
+			{Name: "position", Kind: args.Int, Required: true, Min: 1, Max: savedPlaylistMax},
+		}, words)
+		if err != nil {
+			b.reply(msg, fmt.Sprintf("⚠ Please give the position of the song to remove, e.g. `!playlist remove %s 3`.", name))
+			return
+		}
+		pos := values.Int("position", 0)
+		if pos > len(playlist.Songs) {
+			b.reply(msg, fmt.Sprintf("⚠ **%s** only has %d song(s).", display, len(playlist.Songs)))
+			return
+		}
+		removed := songFromQueued(playlist.Songs[pos-1])
+		playlist.Songs = append(playlist.Songs[:pos-1], playlist.Songs[pos:]...)
+		if !b.savePlaylist(msg, key, playlist) {
+			return
+		}
+		b.reply(msg, fmt.Sprintf("🗑 Removed %s from **%s**.", removed.Name(), display))
+
+	case "delete":
+		if !b.canEditPlaylist(msg, playlist) {
+			b.reply(msg, fmt.Sprintf("⛔ Only the person who created **%s** or a server manager can delete it.", display))
+			return
+		}
+		if err := playlists.Delete(key); err != nil {
+			log.Printf("Error deleting playlist %s: %v", key, err)
+			b.reply(msg, "⚠ Couldn't delete the playlist, please try again.")
+			return
+		}
+		b.reply(msg, fmt.Sprintf("🗑 Deleted **%s**.", display))
+	}
+}
+
+func (pc PlaylistCommand) Help() string {
+	return "Saves named playlists to replay later. Names starting with server: are shared with the whole server."
+}
+
+func (pc PlaylistCommand) Args() []args.Spec {
+	return []args.Spec{
+		{Name: "action", Description: "What to do", Kind: args.Enum, Required: true,
+			Choices: []string{"list", "create", "save", "add", "remove", "show", "play", "delete"}},
+		{Name: "name", Description: "Playlist name, prefixed with server: to share it", Kind: args.String},
+		{Name: "songs", Description: "Links or a search for create and add, a position for remove", Kind: args.Text},
+	}
+}
+
+func (pc PlaylistCommand) Category() string { return "Music" }
+
+func (pc PlaylistCommand) Examples() []string {
+	return []string{
+		"!playlist create chill https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		"!playlist add chill never gonna give you up",
+		"!playlist save server:gamenight",
+		"!playlist play server:gamenight",
+		"!playlist remove chill 2",
+		"!playlist list",
+	}
+}
+
+func (pc PlaylistCommand) Deferred() bool { return true }
+
+// playlistKey returns the store key for a playlist name along with the name as
+// shown to users.
+func playlistKey(msg *discordgo.MessageCreate, name string) (key, display string, err error) {
+	shared := false
+	// Lowercasing can change the length of the rest of the name, so only the
+	// prefix itself is compared.
+	if len(name) >= len(sharedPlaylistPrefix) && strings.EqualFold(name[:len(sharedPlaylistPrefix)], sharedPlaylistPrefix) {
+		shared = true
+		name = name[len(sharedPlaylistPrefix):]
+	}
+	if name == "" || len(name) > 50 || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("playlist names must be 1-50 characters without slashes")
+	}
+
+	if shared {
+		if msg.GuildID == "" {
+			return "", "", fmt.Errorf("shared playlists only exist in servers")
+		}
+		return "guild/" + msg.GuildID + "/" + strings.ToLower(name), sharedPlaylistPrefix + name, nil
+	}
+	return "user/" + msg.Author.ID + "/" + strings.ToLower(name), name, nil
+}
+
+// canEditPlaylist reports whether the author of msg may change a playlist:
+// its creator always can, and server managers can change shared ones.
+func (b *BotController) canEditPlaylist(msg *discordgo.MessageCreate, playlist storage.SavedPlaylist) bool {
+	if playlist.OwnerID == msg.Author.ID {
+		return true
+	}
+	if !strings.HasPrefix(playlist.Name, sharedPlaylistPrefix) {
+		return false
+	}
+	perms, err := b.Session.UserChannelPermissions(msg.Author.ID, msg.ChannelID)
+	if err != nil {
+		log.Printf("Error getting permissions for user %s in channel %s: %v", msg.Author.ID, msg.ChannelID, err)
+		return false
+	}
+	return perms&(discordgo.PermissionManageServer|discordgo.PermissionAdministrator) != 0
+}
+
+func (b *BotController) savePlaylist(msg *discordgo.MessageCreate, key string, playlist storage.SavedPlaylist) bool {
+	playlist.Updated = time.Now()
+	err := storage.NewRepository[storage.SavedPlaylist](b.Store, storage.BucketPlaylists).Put(key, playlist)
+	if err != nil {
+		log.Printf("Error saving playlist %s: %v", key, err)
+		b.reply(msg, "⚠ Couldn't save the playlist, please try again.")
+		return false
+	}
+	return true
+}
+
+// queuedSongs returns the current and upcoming songs of a guild's queue to be
+// saved, leaving out uploads since their links expire. It also returns how many
+// uploads were left out.
+func (b *BotController) queuedSongs(gs *GuildSession) ([]storage.QueuedSong, int) {
+	var songs []storage.QueuedSong
+	uploads := 0
+	queued := gs.queue.Songs()
+	if current := gs.queue.Current(); current != nil {
+		queued = append([]*Song{current}, queued...)
+	}
+	for _, song := range queued {
+		if song.upload {
+			uploads++
+			continue
+		}
+		songs = append(songs, queuedSong(song))
+	}
+	return songs, uploads
+}
+
+// resolveSongs turns links, YouTube playlists or a search into songs without
+// queueing them. Searches take the top result.
+func (b *BotController) resolveSongs(msg *discordgo.MessageCreate, query string) []*Song {
+	words, _ := args.Split(query)
+	links, err := args.Parse(linksSpec, words)
+	if err != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), searchTimeout)
+		defer cancel()
+		results, err := audio.Search(ctx, binPath("yt-dlp.exe"), query, 1)
+		if err != nil {
+			log.Println("❌ Error searching YouTube:", err)
+			b.reply(msg, "⚠ Couldn't search YouTube, please try again.")
+			return nil
+		}
+		if len(results) == 0 {
+			b.reply(msg, fmt.Sprintf("🔎 No results for **%s**.", query))
+			return nil
+		}
+		return []*Song{songFromResult(results[0], msg.Author.ID)}
+	}
+
+	var songs, single []*Song
+	for _, link := range links.Strings("links") {
+		if !audio.IsPlaylist(link) {
+			single = append(single, &Song{URL: link, RequestedBy: msg.Author.ID})
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), playlistTimeout)
+		results, err := audio.Playlist(ctx, binPath("yt-dlp.exe"), link, savedPlaylistMax)
+		cancel()
+		if err != nil {
+			log.Println("❌ Error listing playlist:", err)
+			b.reply(msg, fmt.Sprintf("⚠ Couldn't load playlist: %s", link))
+			continue
+		}
+		for _, r := range results {
+			songs = append(songs, songFromResult(r, msg.Author.ID))
+		}
+	}
+	if len(single) > 0 {
+		songs = append(songs, b.lookupSongs(msg, single)...)
+	}
+	return songs
+}
+
+// formatPlaylists lists the author's playlists and the server's shared ones.
+func (b *BotController) formatPlaylists(msg *discordgo.MessageCreate) string {
+	all, err := storage.NewRepository[storage.SavedPlaylist](b.Store, storage.BucketPlaylists).All()
+	if err != nil {
+		log.Printf("Error loading playlists: %v", err)
+		return "⚠ Couldn't load playlists, please try again."
+	}
+
+	var mine, shared []string
+	for key, playlist := range all {
+		line := fmt.Sprintf("• **%s** — %d song(s)", playlist.Name, len(playlist.Songs))
+		switch {
+		case strings.HasPrefix(key, "user/"+msg.Author.ID+"/"):
+			mine = append(mine, line)
+		case msg.GuildID != "" && strings.HasPrefix(key, "guild/"+msg.GuildID+"/"):
+			shared = append(shared, line)
+		}
+	}
+	if len(mine) == 0 && len(shared) == 0 {
+		return "You don't have any playlists yet. Create one with `!playlist create <name>` or save the queue with `!playlist save <name>`."
+	}
+	sort.Strings(mine)
+	sort.Strings(shared)
+
+	var sb strings.Builder
+	if len(mine) > 0 {
+		sb.WriteString("**Your playlists**\n" + strings.Join(mine, "\n") + "\n")
+	}
+	if len(shared) > 0 {
+		sb.WriteString("**Server playlists**\n" + strings.Join(shared, "\n") + "\n")
+	}
+	return sb.String()
+}
+
+// playlistEmbed lists the songs of a saved playlist.
+func playlistEmbed(playlist storage.SavedPlaylist) *discordgo.MessageEmbed {
+	var total time.Duration
+	var lines []string
+	for i, saved := range playlist.Songs {
+		song := songFromQueued(saved)
+		total += song.Duration
+		if i >= playlistShowLines {
+			continue
+		}
+		line := fmt.Sprintf("`%d.` %s", i+1, song.Link())
+		if song.Duration > 0 {
+			line += fmt.Sprintf(" `[%s]`", formatDuration(song.Duration))
+		}
+		lines = append(lines, line)
+	}
+	if extra := len(playlist.Songs) - playlistShowLines; extra > 0 {
+		lines = append(lines, fmt.Sprintf("…and %d more", extra))
+	}
+
+	description := strings.Join(lines, "\n")
+	if description == "" {
+		description = "This playlist is empty. Add songs with `!playlist add`."
+	}
+	return &discordgo.MessageEmbed{
+		Title:       "📜 " + playlist.Name,
+		Description: description,
+		Color:       queueColor,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Created by", Value: "<@" + playlist.OwnerID + ">", Inline: true},
+		},
+		Footer: &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("%d songs • %s", len(playlist.Songs), formatDuration(total))},
+	}
+}
@@ -0,0 +1,45 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestPlaylistKey(t *testing.T) {
+	tests := []struct {
+		guild, name  string
+		key, display string // Empty key when the name is refused.
+	}{
+		{"g1", "GameNight", "user/u1/gamenight", "GameNight"},
+		{"g1", "server:GameNight", "guild/g1/gamenight", "server:GameNight"},
+		{"g1", "SERVER:GameNight", "guild/g1/gamenight", "server:GameNight"},
+		{"g1", "Server:Ärger", "guild/g1/ärger", "server:Ärger"},
+		{"g1", "server:İstanbul", "guild/g1/" + strings.ToLower("İstanbul"), "server:İstanbul"},
+		{"g1", "servers", "user/u1/servers", "servers"},
+		{"g1", "server:", "", ""},
+		{"g1", "server:a/b", "", ""},
+		{"g1", "", "", ""},
+		{"g1", strings.Repeat("a", 51), "", ""},
+		{"g1", "server:" + strings.Repeat("a", 50), "guild/g1/" + strings.Repeat("a", 50), "server:" + strings.Repeat("a", 50)},
+		{"", "server:GameNight", "", ""},
+		{"", "GameNight", "user/u1/gamenight", "GameNight"},
+	}
+	for _, tt := range tests {
+		msg := &discordgo.MessageCreate{Message: &discordgo.Message{
+			GuildID: tt.guild,
+			Author:  &discordgo.User{ID: "u1"},
+		}}
+		key, display, err := playlistKey(msg, tt.name)
+		if tt.key == "" {
+			if err == nil {
+				t.Errorf("playlistKey(%q) in guild %q = %q, want it refused", tt.name, tt.guild, key)
+			}
+			continue
+		}
+		if err != nil || key != tt.key || display != tt.display {
+			t.Errorf("playlistKey(%q) in guild %q = %q, %q, %v; want %q, %q", tt.name, tt.guild, key, display, err, tt.key, tt.display)
+		}
+	}
+}
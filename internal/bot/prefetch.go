@@ -0,0 +1,54 @@
+package bot
+
+import "github.com/AjStraight619/discord-bot/internal/audio"
+
+// prefetchDepth is how many upcoming songs are downloaded ahead of time.
+const prefetchDepth = 2
+
+// priorityNow is the download priority of the song that's about to play.
+// Prefetched songs use their queue position, so the next song comes first.
+const priorityNow = 0
+
+// prefetch downloads the next few songs into the audio cache while the
+// current one plays, so they start from disk. Nothing is prefetched while the
+// queue is idle; the first song streams as soon as playback starts.
+func (b *BotController) prefetch(gs *GuildSession) {
+	if b.Downloads == nil || b.AudioCache == nil || gs.queue.Current() == nil {
+		return
+	}
+	songs := gs.queue.Songs()
+	for i, song := range songs[:min(len(songs), prefetchDepth)] {
+		if song.File != "" {
+			continue
+		}
+		if _, ok := b.AudioCache.Get(audio.CacheKey(song.URL)); ok {
+			continue
+		}
+		b.Downloads.Request(song.URL, i+1, song)
+	}
+}
+
+// cancelDownloads stops downloading songs that were taken out of the queue,
+// unless another queue entry, here or in another guild, is waiting for the
+// same song.
+func (b *BotController) cancelDownloads(songs ...*Song) {
+	if b.Downloads == nil {
+		return
+	}
+	for _, song := range songs {
+		b.Downloads.Cancel(song.URL, song)
+	}
+}
+
+// downloadProgress returns how far a song's download has got, if it's being
+// downloaded.
+func (b *BotController) downloadProgress(song *Song) (float64, bool) {
+	if b.Downloads == nil {
+		return 0, false
+	}
+	job, ok := b.Downloads.Lookup(song.URL)
+	if !ok {
+		return 0, false
+	}
+	return job.Progress(), true
+}
@@ -0,0 +1,202 @@
+package bot
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"sync"
+)
+
+// LoopMode controls what happens when a song finishes.
+type LoopMode int
+
+const (
+	LoopOff   LoopMode = iota // Play the queue once.
+	LoopTrack                 // Repeat the current song until it's skipped.
+	LoopQueue                 // Send finished songs to the back of the queue.
+)
+
+func (m LoopMode) String() string {
+	switch m {
+	case LoopTrack:
+		return "track"
+	case LoopQueue:
+		return "queue"
+	default:
+		return "off"
+	}
+}
+
+// Queue is a guild's song queue. It's shared by the commands that edit it and
+// the goroutine that plays it, so every access goes through its lock.
+type Queue struct {
+	mu      sync.Mutex
+	songs   []*Song // Upcoming songs, next first.
+	current *Song
+	loop    LoopMode
+	playing bool
+}
+
+// Add appends songs to the end of the queue and returns the new length.
+func (q *Queue) Add(songs ...*Song) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.songs = append(q.songs, songs...)
+	return len(q.songs)
+}
+
+// Next finishes the current song and makes the next one current, following the
+// loop mode. A skipped song isn't repeated by LoopTrack. It returns nil and
+// marks the queue as no longer playing once the queue is empty.
+func (q *Queue) Next(skipped bool) *Song {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.current != nil {
+		switch {
+		case q.loop == LoopTrack && !skipped:
+			return q.current
+		case q.loop == LoopQueue:
+			q.songs = append(q.songs, q.current)
+		}
+	}
+
+	q.current = nil
+	if len(q.songs) == 0 {
+		q.playing = false
+		return nil
+	}
+	q.current = q.songs[0]
+	q.songs = q.songs[1:]
+	return q.current
+}
+
+// Drop forgets the current song without repeating or requeueing it, e.g. when
+// it failed to download.
+func (q *Queue) Drop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.current = nil
+}
+
+// Current returns the song being played, or nil.
+func (q *Queue) Current() *Song {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.current
+}
+
+// Songs returns a copy of the upcoming songs.
+func (q *Queue) Songs() []*Song {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return slices.Clone(q.songs)
+}
+
+// Len returns the number of upcoming songs.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.songs)
+}
+
+// Remove takes the song at a 1-based position out of the queue.
+func (q *Queue) Remove(pos int) (*Song, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if pos < 1 || pos > len(q.songs) {
+		return nil, fmt.Errorf("there's no song at position %d", pos)
+	}
+	song := q.songs[pos-1]
+	q.songs = slices.Delete(q.songs, pos-1, pos)
+	return song, nil
+}
+
+// Move moves the song at one 1-based position to another.
+func (q *Queue) Move(from, to int) (*Song, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if from < 1 || from > len(q.songs) {
+		return nil, fmt.Errorf("there's no song at position %d", from)
+	}
+	if to < 1 || to > len(q.songs) {
+		return nil, fmt.Errorf("position %d is outside the queue", to)
+	}
+	song := q.songs[from-1]
+	q.songs = slices.Delete(q.songs, from-1, from)
+	q.songs = slices.Insert(q.songs, to-1, song)
+	return song, nil
+}
+
+// Shuffle randomizes the order of the upcoming songs.
+func (q *Queue) Shuffle() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	rand.Shuffle(len(q.songs), func(i, j int) {
+		q.songs[i], q.songs[j] = q.songs[j], q.songs[i]
+	})
+}
+
+// Clear removes every upcoming song and returns them.
+func (q *Queue) Clear() []*Song {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	songs := q.songs
+	q.songs = nil
+	return songs
+}
+
+// Stop clears the queue and forgets the current song so no loop mode brings it
+// back.
+func (q *Queue) Stop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.songs = nil
+	q.current = nil
+}
+
+// Loop returns the loop mode.
+func (q *Queue) Loop() LoopMode {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.loop
+}
+
+// SetLoop changes the loop mode.
+func (q *Queue) SetLoop(mode LoopMode) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.loop = mode
+}
+
+// StartPlaying marks the queue as being played. It reports false if it
+// already was, so only one goroutine plays a queue at a time.
+func (q *Queue) StartPlaying() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.playing {
+		return false
+	}
+	q.playing = true
+	return true
+}
+
+// Requeue puts the current song back at the front of the queue and marks the
+// queue as no longer being played, for when playback stops part way through.
+func (q *Queue) Requeue() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.current != nil {
+		q.songs = slices.Insert(q.songs, 0, q.current)
+	}
+	q.current = nil
+	q.playing = false
+}
+
+// StopPlaying marks the queue as no longer being played.
+func (q *Queue) StopPlaying() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.playing = false
+	q.current = nil
+}
@@ -0,0 +1,145 @@
+package bot
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// newQueue returns a queue of songs titled after names.
+func newQueue(names ...string) *Queue {
+	q := &Queue{}
+	for _, name := range names {
+		q.Add(&Song{Title: name})
+	}
+	return q
+}
+
+// titles lists the titles of songs, "-" standing for nil.
+func titles(songs ...*Song) string {
+	names := make([]string, len(songs))
+	for i, song := range songs {
+		if song == nil {
+			names[i] = "-"
+		} else {
+			names[i] = song.Title
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+func TestQueueNextLoopModes(t *testing.T) {
+	tests := []struct {
+		loop    LoopMode
+		skipped bool
+		want    string // Songs played in turn, with "-" once the queue ends.
+	}{
+		{LoopOff, false, "a b c - -"},
+		{LoopOff, true, "a b c - -"},
+		{LoopTrack, false, "a a a a a"},
+		{LoopTrack, true, "a b c - -"},
+		{LoopQueue, false, "a b c a b"},
+		{LoopQueue, true, "a b c a b"},
+	}
+	for _, tt := range tests {
+		q := newQueue("a", "b", "c")
+		q.SetLoop(tt.loop)
+		q.StartPlaying()
+
+		var played []*Song
+		for i := 0; i < 5; i++ {
+			played = append(played, q.Next(tt.skipped && i > 0))
+		}
+		if got := titles(played...); got != tt.want {
+			t.Errorf("loop %s, skipped %v: played %q, want %q", tt.loop, tt.skipped, got, tt.want)
+		}
+		// StartPlaying only succeeds once the queue has stopped.
+		if ended := strings.HasSuffix(tt.want, "-"); q.StartPlaying() != ended {
+			t.Errorf("loop %s, skipped %v: queue marked as stopped is %v, want %v", tt.loop, tt.skipped, !ended, ended)
+		}
+	}
+}
+
+func TestQueueMove(t *testing.T) {
+	tests := []struct {
+		from, to int
+		want     string
+		wantErr  bool
+	}{
+		{1, 3, "b c a", false},
+		{3, 1, "c a b", false},
+		{2, 2, "a b c", false},
+		{0, 1, "a b c", true},
+		{4, 1, "a b c", true},
+		{1, 0, "a b c", true},
+		{1, 4, "a b c", true},
+	}
+	for _, tt := range tests {
+		q := newQueue("a", "b", "c")
+		song, err := q.Move(tt.from, tt.to)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Move(%d, %d) error = %v, want error %v", tt.from, tt.to, err, tt.wantErr)
+		}
+		if err == nil && song.Title != titles(q.Songs()[tt.to-1]) {
+			t.Errorf("Move(%d, %d) returned %q, which isn't at %d", tt.from, tt.to, song.Title, tt.to)
+		}
+		if got := titles(q.Songs()...); got != tt.want {
+			t.Errorf("Move(%d, %d) left %q, want %q", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestQueueRemove(t *testing.T) {
+	tests := []struct {
+		pos     int
+		removed string
+		want    string
+	}{
+		{1, "a", "b c"},
+		{3, "c", "a b"},
+		{0, "", "a b c"},
+		{4, "", "a b c"},
+		{-1, "", "a b c"},
+	}
+	for _, tt := range tests {
+		q := newQueue("a", "b", "c")
+		song, err := q.Remove(tt.pos)
+		if tt.removed == "" {
+			if err == nil {
+				t.Errorf("Remove(%d) removed %q, want an error", tt.pos, song.Title)
+			}
+		} else if err != nil || song.Title != tt.removed {
+			t.Errorf("Remove(%d) = %v, %v; want %q", tt.pos, song, err, tt.removed)
+		}
+		if got := titles(q.Songs()...); got != tt.want {
+			t.Errorf("Remove(%d) left %q, want %q", tt.pos, got, tt.want)
+		}
+	}
+}
+
+func TestQueueShuffleKeepsSongs(t *testing.T) {
+	q := newQueue("a", "b", "c", "d", "e")
+	q.Shuffle()
+	got := strings.Fields(titles(q.Songs()...))
+	slices.Sort(got)
+	if strings.Join(got, " ") != "a b c d e" {
+		t.Errorf("shuffled queue has %q, want the same songs", got)
+	}
+}
+
+func TestQueueRequeue(t *testing.T) {
+	q := newQueue("a", "b")
+	q.StartPlaying()
+	q.Next(false)
+	q.Requeue()
+
+	if q.Current() != nil {
+		t.Errorf("current song is %q after Requeue, want none", q.Current().Title)
+	}
+	if got := titles(q.Songs()...); got != "a b" {
+		t.Errorf("queue is %q after Requeue, want the current song back in front", got)
+	}
+	if !q.StartPlaying() {
+		t.Error("queue still marked as playing after Requeue")
+	}
+}
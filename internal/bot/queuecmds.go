@@ -0,0 +1,244 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AjStraight619/discord-bot/internal/args"
+	"github.com/bwmarrin/discordgo"
+)
+
+// queuePageSize is the number of songs listed on each page of !queue.
+const queuePageSize = 10
+
+const queueColor = 0x1DB954
+
+// QueueCommand lists the current song and the upcoming queue.
+type QueueCommand struct{}
+
+func (qc QueueCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	b.replyComplex(msg, b.queuePage(msg.GuildID, opts.Int("page", 1)-1))
+}
+
+func (qc QueueCommand) Help() string { return "Lists the songs in the queue." }
+
+func (qc QueueCommand) Args() []args.Spec {
+	return []args.Spec{
+		{Name: "page", Description: "Page of the queue to show", Kind: args.Int, Min: 1, Max: 1000},
+	}
+}
+
+func (qc QueueCommand) Category() string { return "Music" }
+
+func (qc QueueCommand) Examples() []string { return []string{"!queue", "!queue 2"} }
+
+// queuePage renders one page of a guild's queue with buttons to flip pages.
+func (b *BotController) queuePage(guildID string, page int) *discordgo.MessageSend {
+	gs := b.Sessions.Get(guildID)
+	current := gs.queue.Current()
+	songs := gs.queue.Songs()
+
+	pages := (len(songs) + queuePageSize - 1) / queuePageSize
+	page = max(0, min(page, pages-1))
+
+	var total time.Duration
+	for _, song := range songs {
+		total += song.Duration
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: "🎶 Queue",
+		Color: queueColor,
+		Footer: &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Page %d/%d • %d songs • %s • Loop: %s",
+			page+1, max(pages, 1), len(songs), formatDuration(total), gs.queue.Loop())},
+	}
+
+	if current != nil {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Now playing", Value: b.queueLine(current)})
+	}
+
+	if len(songs) == 0 {
+		embed.Description = "The queue is empty. Add songs with `!play`."
+	} else {
+		var lines []string
+		start := page * queuePageSize
+		end := min(start+queuePageSize, len(songs))
+		for i, song := range songs[start:end] {
+			lines = append(lines, fmt.Sprintf("`%d.` %s", start+i+1, b.queueLine(song)))
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Up next", Value: strings.Join(lines, "\n")})
+	}
+
+	return &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: pagerButtons("queue", page, pages),
+	}
+}
+
+func (b *BotController) queueLine(song *Song) string {
+	line := song.Link()
+	if song.Duration > 0 {
+		line += fmt.Sprintf(" `[%s]`", formatDuration(song.Duration))
+	}
+	if percent, ok := b.downloadProgress(song); ok {
+		line += fmt.Sprintf(" 📥 %.0f%%", percent)
+	}
+	if song.RequestedBy != "" {
+		line += fmt.Sprintf(" — <@%s>", song.RequestedBy)
+	}
+	return line
+}
+
+func (b *BotController) handleQueuePage(s *discordgo.Session, i *discordgo.InteractionCreate, payload string) {
+	page, err := strconv.Atoi(payload)
+	if err != nil {
+		log.Printf("Invalid queue page %q: %v", payload, err)
+		return
+	}
+
+	data := b.queuePage(i.GuildID, page)
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     data.Embeds,
+			Components: data.Components,
+		},
+	})
+	if err != nil {
+		log.Printf("Error updating queue page: %v", err)
+	}
+}
+
+// RemoveCommand removes a song from the queue by position.
+type RemoveCommand struct{}
+
+func (rc RemoveCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	gs := b.Sessions.Get(msg.GuildID)
+	song, err := gs.queue.Remove(opts.Int("position", 0))
+	if err != nil {
+		b.reply(msg, fmt.Sprintf("⚠ %s.", err))
+		return
+	}
+	b.cancelDownloads(song)
+	b.prefetch(gs)
+	b.saveQueue(gs)
+	b.reply(msg, fmt.Sprintf("🗑 Removed %s from the queue.", song.Name()))
+}
+
+func (rc RemoveCommand) Help() string { return "Removes a song from the queue." }
+
+func (rc RemoveCommand) Args() []args.Spec {
+	return []args.Spec{
+		{Name: "position", Description: "Position of the song in !queue", Kind: args.Int, Required: true, Min: 1, Max: 10000},
+	}
+}
+
+func (rc RemoveCommand) Category() string { return "Music" }
+
+func (rc RemoveCommand) Examples() []string { return []string{"!remove 3"} }
+
+// MoveCommand moves a song to another position in the queue.
+type MoveCommand struct{}
+
+func (mc MoveCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	gs := b.Sessions.Get(msg.GuildID)
+	to := opts.Int("to", 0)
+	song, err := gs.queue.Move(opts.Int("from", 0), to)
+	if err != nil {
+		b.reply(msg, fmt.Sprintf("⚠ %s.", err))
+		return
+	}
+	b.prefetch(gs)
+	b.saveQueue(gs)
+	b.reply(msg, fmt.Sprintf("↕ Moved %s to position %d.", song.Name(), to))
+}
+
+func (mc MoveCommand) Help() string { return "Moves a song to another position in the queue." }
+
+func (mc MoveCommand) Args() []args.Spec {
+	return []args.Spec{
+		{Name: "from", Description: "Current position of the song", Kind: args.Int, Required: true, Min: 1, Max: 10000},
+		{Name: "to", Description: "New position for the song", Kind: args.Int, Required: true, Min: 1, Max: 10000},
+	}
+}
+
+func (mc MoveCommand) Category() string { return "Music" }
+
+func (mc MoveCommand) Examples() []string { return []string{"!move 5 1"} }
+
+// ShuffleCommand shuffles the upcoming songs.
+type ShuffleCommand struct{}
+
+func (sc ShuffleCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	gs := b.Sessions.Get(msg.GuildID)
+	if gs.queue.Len() < 2 {
+		b.reply(msg, "⚠ There aren't enough songs in the queue to shuffle.")
+		return
+	}
+	gs.queue.Shuffle()
+	b.prefetch(gs)
+	b.saveQueue(gs)
+	b.reply(msg, "🔀 Shuffled the queue.")
+}
+
+func (sc ShuffleCommand) Help() string { return "Shuffles the upcoming songs." }
+
+func (sc ShuffleCommand) Args() []args.Spec { return nil }
+
+func (sc ShuffleCommand) Category() string { return "Music" }
+
+func (sc ShuffleCommand) Examples() []string { return []string{"!shuffle"} }
+
+// ClearCommand removes every upcoming song but keeps the current one playing.
+type ClearCommand struct{}
+
+func (cc ClearCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	gs := b.Sessions.Get(msg.GuildID)
+	removed := gs.queue.Clear()
+	b.cancelDownloads(removed...)
+	b.saveQueue(gs)
+	b.reply(msg, fmt.Sprintf("🗑 Cleared %d song(s) from the queue.", len(removed)))
+}
+
+func (cc ClearCommand) Help() string { return "Removes every upcoming song from the queue." }
+
+func (cc ClearCommand) Args() []args.Spec { return nil }
+
+func (cc ClearCommand) Category() string { return "Music" }
+
+func (cc ClearCommand) Examples() []string { return []string{"!clear"} }
+
+// LoopCommand repeats the current song or the whole queue.
+type LoopCommand struct{}
+
+var loopModes = map[string]LoopMode{"off": LoopOff, "track": LoopTrack, "queue": LoopQueue}
+
+func (lc LoopCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	gs := b.Sessions.Get(msg.GuildID)
+	mode := loopModes[opts.String("mode")]
+	gs.queue.SetLoop(mode)
+
+	switch mode {
+	case LoopTrack:
+		b.reply(msg, "🔂 Looping the current song.")
+	case LoopQueue:
+		b.reply(msg, "🔁 Looping the queue.")
+	default:
+		b.reply(msg, "➡ Looping is off.")
+	}
+}
+
+func (lc LoopCommand) Help() string { return "Repeats the current song or the whole queue." }
+
+func (lc LoopCommand) Args() []args.Spec {
+	return []args.Spec{
+		{Name: "mode", Description: "What to repeat", Kind: args.Enum, Required: true, Choices: []string{"off", "track", "queue"}},
+	}
+}
+
+func (lc LoopCommand) Category() string { return "Music" }
+
+func (lc LoopCommand) Examples() []string { return []string{"!loop track", "!loop queue", "!loop off"} }
@@ -0,0 +1,126 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/AjStraight619/discord-bot/internal/receive"
+	"github.com/bwmarrin/discordgo"
+)
+
+// speakerFlush is how long a speaker is quiet before packets held back by the
+// jitter buffer are played anyway.
+const speakerFlush = 100 * time.Millisecond
+
+// voiceReceiver is a guild's running receiver and the pump feeding it.
+type voiceReceiver struct {
+	*receive.Receiver
+	cancel context.CancelFunc
+}
+
+// subscribeVoice subscribes to what one user, or everyone when userID is
+// empty, says in the bot's voice channel. The bot joins requester's channel if
+// it isn't in one, and rejoins undeafened if it has to.
+func (b *BotController) subscribeVoice(gs *GuildSession, userID, requester string) (*receive.Subscription, error) {
+	gs.mu.Lock()
+	vr := gs.receiver
+	if vr == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		vr = &voiceReceiver{Receiver: receive.New(), cancel: cancel}
+		gs.receiver = vr
+		go b.pumpVoice(ctx, gs, vr.Receiver)
+	}
+	sub := vr.Subscribe(userID)
+	gs.mu.Unlock()
+
+	if _, err := b.listenVoice(gs, requester); err != nil {
+		b.unsubscribeVoice(gs, sub)
+		return nil, err
+	}
+	return sub, nil
+}
+
+// unsubscribeVoice ends a subscription, stopping the guild's receiver once
+// nobody is listening so later joins can be deafened again.
+func (b *BotController) unsubscribeVoice(gs *GuildSession, sub *receive.Subscription) {
+	sub.Close()
+
+	gs.mu.Lock()
+	vr := gs.receiver
+	if vr == nil || vr.Subscribers() > 0 {
+		gs.mu.Unlock()
+		return
+	}
+	gs.receiver = nil
+	gs.mu.Unlock()
+	vr.cancel()
+	vr.Close()
+}
+
+// stopReceiving ends every subscription to the guild's voice, e.g. when the
+// bot leaves the channel.
+func (b *BotController) stopReceiving(gs *GuildSession) {
+	gs.mu.Lock()
+	vr := gs.receiver
+	gs.receiver = nil
+	gs.mu.Unlock()
+	if vr != nil {
+		vr.cancel()
+		vr.Close()
+	}
+}
+
+// listenVoice returns a voice connection the bot hears the channel on. The
+// bot joins the requester's channel if it isn't in one, and rejoins
+// undeafened if it joined deafened, since discordgo only receives audio on
+// connections opened undeafened.
+func (b *BotController) listenVoice(gs *GuildSession, userID string) (*discordgo.VoiceConnection, error) {
+	gs.mu.Lock()
+	vc, muted := gs.VoiceConn, gs.voiceMuted
+	gs.mu.Unlock()
+	if vc == nil {
+		return b.joinUserChannel(gs.GuildID, userID, false, false)
+	}
+
+	vc.RLock()
+	receiving := vc.Ready && vc.OpusRecv != nil
+	vc.RUnlock()
+	if receiving {
+		return vc, nil
+	}
+	return b.rejoinVoice(gs, muted, false)
+}
+
+// pumpVoice feeds the packets the guild's voice connection receives to rc
+// until ctx is cancelled, moving to the new connection after a reconnect.
+func (b *BotController) pumpVoice(ctx context.Context, gs *GuildSession, rc *receive.Receiver) {
+	ticker := time.NewTicker(speakerFlush / 2)
+	defer ticker.Stop()
+
+	var vc *discordgo.VoiceConnection
+	var packets <-chan *discordgo.Packet
+	for {
+		if current := gs.Voice(); current != nil && current != vc {
+			vc = current
+			vc.AddHandler(rc.Speaking)
+			vc.RLock()
+			packets = vc.OpusRecv
+			vc.RUnlock()
+			log.Printf("👂 Receiving voice in guild %s", gs.GuildID)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case p, ok := <-packets:
+			if !ok {
+				packets = nil
+				continue
+			}
+			rc.Push(p)
+		case <-ticker.C:
+			rc.Flush(speakerFlush)
+		}
+	}
+}
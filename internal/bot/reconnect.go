@@ -0,0 +1,121 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Reconnecting to voice is retried with a delay that doubles from
+// reconnectMinDelay up to reconnectMaxDelay, reconnectAttempts times.
+const (
+	reconnectAttempts = 6
+	reconnectMinDelay = time.Second
+	reconnectMaxDelay = 30 * time.Second
+)
+
+// voiceReady reports whether a voice connection is up and taking audio.
+func voiceReady(vc *discordgo.VoiceConnection) bool {
+	vc.RLock()
+	defer vc.RUnlock()
+	return vc.Ready
+}
+
+// reconnectVoice brings back a guild's lost voice connection, rejoining its
+// last voice channel with backoff. discordgo also retries on its own, so a
+// connection that came back in the meantime is used as is. It gives up when
+// ctx is cancelled, e.g. because the song was skipped.
+func (b *BotController) reconnectVoice(ctx context.Context, gs *GuildSession) (*discordgo.VoiceConnection, error) {
+	gs.mu.Lock()
+	gs.reconnecting = true
+	gs.mu.Unlock()
+	defer func() {
+		gs.mu.Lock()
+		gs.reconnecting = false
+		gs.mu.Unlock()
+	}()
+
+	delay := reconnectMinDelay
+	for attempt := 1; attempt <= reconnectAttempts; attempt++ {
+		if vc := gs.Voice(); vc != nil && voiceReady(vc) {
+			return vc, nil
+		}
+		channelID := gs.VoiceChannel()
+		if channelID == "" {
+			return nil, fmt.Errorf("no voice channel to rejoin")
+		}
+
+		log.Printf("📡 Reconnecting to voice in guild %s (attempt %d/%d)...", gs.GuildID, attempt, reconnectAttempts)
+		vc, err := b.joinChannel(gs.GuildID, channelID, false, !gs.listening())
+		if err == nil {
+			log.Printf("✅ Reconnected to voice in guild %s", gs.GuildID)
+			return vc, nil
+		}
+		log.Printf("❌ Error reconnecting to voice in guild %s: %v", gs.GuildID, err)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		delay = min(delay*2, reconnectMaxDelay)
+	}
+	return nil, fmt.Errorf("gave up after %d attempts", reconnectAttempts)
+}
+
+// rejoinVoice drops the guild's voice connection and joins its channel again
+// with new settings, e.g. undeafened so the bot can hear it. Playback notices
+// the gap and carries on over the new connection.
+func (b *BotController) rejoinVoice(gs *GuildSession, mute, deaf bool) (*discordgo.VoiceConnection, error) {
+	gs.mu.Lock()
+	gs.reconnecting = true
+	vc, channelID := gs.VoiceConn, gs.VoiceChannelID
+	gs.mu.Unlock()
+	defer func() {
+		gs.mu.Lock()
+		gs.reconnecting = false
+		gs.mu.Unlock()
+	}()
+
+	if vc != nil {
+		vc.Disconnect()
+	}
+	return b.joinChannel(gs.GuildID, channelID, mute, deaf)
+}
+
+// botVoiceStateUpdate follows the bot's own voice state: being moved to
+// another channel, or disconnected by someone else.
+func (b *BotController) botVoiceStateUpdate(gs *GuildSession, vsu *discordgo.VoiceStateUpdate) {
+	gs.mu.Lock()
+	connected := gs.VoiceConn != nil
+	reconnecting := gs.reconnecting
+	previous := gs.VoiceChannelID
+	gs.mu.Unlock()
+
+	// Leaving on purpose clears the connection first, and discordgo briefly
+	// leaves the channel itself while reconnecting.
+	if !connected || reconnecting {
+		return
+	}
+
+	switch vsu.ChannelID {
+	case "":
+		log.Printf("⚠ Disconnected from voice in guild %s", gs.GuildID)
+		b.LeaveVoiceChannel(gs.GuildID, leftDisconnected)
+	case previous:
+	default:
+		log.Printf("🔀 Moved to voice channel %s in guild %s", vsu.ChannelID, gs.GuildID)
+		gs.mu.Lock()
+		gs.VoiceChannelID = vsu.ChannelID
+		if gs.aloneTimer != nil {
+			gs.aloneTimer.Stop()
+			gs.aloneTimer = nil
+		}
+		gs.mu.Unlock()
+		b.saveQueue(gs)
+		b.checkAlone(gs, vsu.ChannelID)
+	}
+}
@@ -0,0 +1,692 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/AjStraight619/discord-bot/internal/args"
+	"github.com/AjStraight619/discord-bot/internal/receive"
+	"github.com/AjStraight619/discord-bot/internal/recording"
+	"github.com/AjStraight619/discord-bot/internal/storage"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// recordingMax stops a recording someone forgot about.
+	recordingMax = 6 * time.Hour
+	// maxAttachments is how many files Discord takes on one message.
+	maxAttachments = 10
+	// recordingDot marks the bot's nickname while it records, so everyone in
+	// the voice channel can see it.
+	recordingDot = "🔴 "
+)
+
+// voiceRecording is a recording of a guild's voice channel in progress.
+type voiceRecording struct {
+	guildID        string
+	channelID      string // Where status and the finished files are posted.
+	voiceChannelID string
+	dir            string
+	format         recording.Format
+	mix            bool
+	started        time.Time
+	cancel         context.CancelFunc
+	done           chan struct{} // Closed once the files are posted.
+	nick           string        // The bot's nickname before it was marked as recording.
+	renamed        bool
+
+	mu      sync.Mutex
+	consent map[string]bool             // User ID -> opted in, looked up as people speak.
+	tracks  map[string]*recording.Track // User ID -> their audio so far.
+	told    map[string]bool             // Members told they aren't being recorded.
+	posted  bool                        // The files are past the last consent check.
+}
+
+// RecordCommand records the voice channel, one track per person who agreed
+// to it.
+type RecordCommand struct{}
+
+func (rc RecordCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	if b.Store == nil {
+		b.reply(msg, "⚠ Recording isn't available because no database is configured to keep track of who opted in.")
+		return
+	}
+
+	gs := b.Sessions.Get(msg.GuildID)
+	switch opts.String("action") {
+	case "start":
+		b.startRecording(msg, opts.Strings("options"))
+	case "stop":
+		if !b.stopRecording(gs) {
+			b.reply(msg, "⚠ I'm not recording.")
+		}
+	case "status":
+		r := gs.currentRecording()
+		if r == nil {
+			b.reply(msg, "⏹ I'm not recording. Use `!record start` to start.")
+			return
+		}
+		b.reply(msg, b.recordingStatus(r, fmt.Sprintf("🔴 **Recording <#%s>** for %s.", r.voiceChannelID, formatDuration(time.Since(r.started)))))
+	case "optin":
+		b.setRecordingConsent(msg, true)
+	default:
+		b.setRecordingConsent(msg, false)
+	}
+}
+
+func (rc RecordCommand) Help() string {
+	return "Records the voice channel to a track per person who opted in, and posts the files when it stops."
+}
+
+func (rc RecordCommand) Args() []args.Spec {
+	return []args.Spec{
+		{Name: "action", Description: "Start or stop recording, show who's recorded, or opt in or out of being recorded", Kind: args.Enum, Required: true, Choices: []string{"start", "stop", "status", "optin", "optout"}},
+		{Name: "options", Description: "ogg (the default) or wav, and mix to add a track of everyone together", Kind: args.Enum, Variadic: true, Choices: []string{"ogg", "wav", "mix"}},
+	}
+}
+
+func (rc RecordCommand) Category() string { return "Voice" }
+
+func (rc RecordCommand) Examples() []string {
+	return []string{"!record optin", "!record start", "!record start wav mix", "!record status", "!record stop"}
+}
+
+// currentRecording returns the recording in progress, nil when there's none.
+func (gs *GuildSession) currentRecording() *voiceRecording {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	return gs.recording
+}
+
+// recordings returns the recording in progress and the one still being saved,
+// either of which may be missing.
+func (gs *GuildSession) recordings() []*voiceRecording {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	var rs []*voiceRecording
+	for _, r := range []*voiceRecording{gs.recording, gs.savingRecording} {
+		if r != nil {
+			rs = append(rs, r)
+		}
+	}
+	return rs
+}
+
+// startRecording starts recording the bot's voice channel, or the
+// requester's if the bot isn't in one.
+func (b *BotController) startRecording(msg *discordgo.MessageCreate, options []string) {
+	format, mix := recording.Ogg, false
+	for _, option := range options {
+		if option == "mix" {
+			mix = true
+		} else {
+			format = recording.Format(option)
+		}
+	}
+
+	gs := b.Sessions.Get(msg.GuildID)
+	ctx, cancel := context.WithTimeout(context.Background(), recordingMax)
+	r := &voiceRecording{
+		guildID:   msg.GuildID,
+		channelID: msg.ChannelID,
+		dir:       filepath.Join(b.RecordingsDir, msg.GuildID, time.Now().Format("2006-01-02_15-04-05")),
+		format:    format,
+		mix:       mix,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		consent:   make(map[string]bool),
+		tracks:    make(map[string]*recording.Track),
+		told:      make(map[string]bool),
+	}
+
+	gs.mu.Lock()
+	if gs.recording != nil {
+		gs.mu.Unlock()
+		cancel()
+		b.reply(msg, "⚠ I'm already recording. Use `!record stop` first.")
+		return
+	}
+	gs.recording = r
+	gs.mu.Unlock()
+
+	abort := func(reply string) {
+		gs.mu.Lock()
+		gs.recording = nil
+		gs.mu.Unlock()
+		cancel()
+		os.Remove(r.dir)
+		b.reply(msg, reply)
+	}
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		log.Printf("❌ Error creating recording folder %s: %v", r.dir, err)
+		abort("⚠ Couldn't create a folder for the recording.")
+		return
+	}
+	sub, err := b.subscribeVoice(gs, "", msg.Author.ID)
+	if err != nil {
+		log.Printf("❌ Error joining voice to record in guild %s: %v", msg.GuildID, err)
+		abort("⚠ Join a voice channel first so I know what to record.")
+		return
+	}
+
+	r.voiceChannelID = gs.VoiceChannel()
+	r.started = time.Now()
+	b.markRecording(r, true)
+	log.Printf("🔴 Recording voice in guild %s to %s", msg.GuildID, r.dir)
+	go b.record(ctx, gs, sub, r)
+
+	what := strings.ToUpper(string(format))
+	if mix {
+		what += " with a mixed-down track"
+	}
+	b.reply(msg, b.recordingStatus(r, fmt.Sprintf("🔴 **Recording <#%s>** to %s until `!record stop`. Only people who opted in with `!record optin` are recorded.", r.voiceChannelID, what)))
+}
+
+// stopRecording ends a guild's recording once its files are posted. It
+// reports false when nothing was being recorded.
+func (b *BotController) stopRecording(gs *GuildSession) bool {
+	r := gs.currentRecording()
+	if r == nil {
+		return false
+	}
+	r.cancel()
+	<-r.done
+	return true
+}
+
+// record writes each consenting speaker's audio to their track until ctx
+// ends or the bot leaves voice, then saves and posts the files.
+func (b *BotController) record(ctx context.Context, gs *GuildSession, sub *receive.Subscription, r *voiceRecording) {
+	defer close(r.done)
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case frame, ok := <-sub.Frames():
+			if !ok {
+				break loop
+			}
+			b.recordFrame(r, frame)
+		}
+	}
+	b.unsubscribeVoice(gs, sub)
+
+	gs.mu.Lock()
+	if gs.recording == r {
+		gs.recording = nil
+	}
+	gs.savingRecording = r
+	gs.mu.Unlock()
+	defer func() {
+		gs.mu.Lock()
+		if gs.savingRecording == r {
+			gs.savingRecording = nil
+		}
+		gs.mu.Unlock()
+	}()
+	b.markRecording(r, false)
+
+	elapsed := time.Since(r.started)
+	stopped := fmt.Sprintf("⏹ Stopped recording <#%s> after %s", r.voiceChannelID, formatDuration(elapsed))
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		stopped += fmt.Sprintf(", recordings are limited to %s", formatDuration(recordingMax))
+	}
+	log.Printf("⏹ Stopped recording voice in guild %s", r.guildID)
+	b.finishRecording(r, elapsed, stopped)
+}
+
+// recordFrame adds a frame to its speaker's track, if they agreed to be
+// recorded.
+func (b *BotController) recordFrame(r *voiceRecording, frame receive.Frame) {
+	if frame.UserID == "" {
+		return // There's no telling whether they opted in.
+	}
+	if !b.recordingConsent(r, frame.UserID) {
+		r.mu.Lock()
+		told := r.told[frame.UserID]
+		r.told[frame.UserID] = true
+		r.mu.Unlock()
+		if !told {
+			go b.postRecordingNotice(r, fmt.Sprintf("🔇 **%s** isn't being recorded because they haven't opted in with `!record optin`.", b.memberName(r.guildID, frame.UserID)))
+		}
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tracks == nil {
+		return // Finishing.
+	}
+	if !r.consent[frame.UserID] {
+		return // They opted out since the check above.
+	}
+	track, ok := r.tracks[frame.UserID]
+	if !ok {
+		var err error
+		track, err = recording.NewTrack(filepath.Join(r.dir, frame.UserID+".pcm"))
+		if err != nil {
+			log.Printf("❌ Error creating recording track: %v", err)
+			return
+		}
+		r.tracks[frame.UserID] = track
+	}
+	if err := track.Write(frame.SSRC, frame.Timestamp, frame.Received.Sub(r.started), frame.PCM); err != nil {
+		log.Printf("❌ Error writing recording track %s: %v", track.Path, err)
+	}
+}
+
+// finishRecording exports the tracks and posts them, or links to the ones
+// too big to upload.
+func (b *BotController) finishRecording(r *voiceRecording, elapsed time.Duration, stopped string) {
+	r.mu.Lock()
+	tracks := r.tracks
+	r.tracks = nil
+	r.mu.Unlock()
+
+	if len(tracks) == 0 {
+		os.RemoveAll(r.dir)
+		b.postRecordingNotice(r, stopped+". Nobody who opted in said anything, so there's nothing to save.")
+		return
+	}
+	saving := fmt.Sprintf("%d tracks", len(tracks))
+	if len(tracks) == 1 {
+		saving = "the track"
+	}
+	b.postRecordingNotice(r, fmt.Sprintf("%s. Saving %s…", stopped, saving))
+
+	files, err := b.exportRecording(r, tracks, elapsed)
+	if err != nil {
+		log.Printf("❌ Error saving recording in %s: %v", r.dir, err)
+		b.postRecordingNotice(r, "⚠ Something went wrong saving the recording.")
+	}
+	// People can still opt out while the files are being saved, up to here.
+	var paths []string
+	r.mu.Lock()
+	for _, f := range files {
+		if r.optedInLocked(f.users) {
+			paths = append(paths, f.path)
+		} else {
+			os.Remove(f.path)
+		}
+	}
+	r.posted = true
+	r.mu.Unlock()
+	if len(paths) == 0 {
+		return
+	}
+	b.postRecordingFiles(r, paths)
+}
+
+// recordedFile is an exported file and the users whose audio is in it.
+type recordedFile struct {
+	path  string
+	users []string
+}
+
+// optedIn reports whether all of users still agree to be recorded.
+func (r *voiceRecording) optedIn(users ...string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.optedInLocked(users)
+}
+
+func (r *voiceRecording) optedInLocked(users []string) bool {
+	for _, userID := range users {
+		if !r.consent[userID] {
+			return false
+		}
+	}
+	return true
+}
+
+// exportRecording turns the raw tracks into files named after the speakers,
+// plus the mix if one was asked for. Anyone who opted out in the meantime is
+// left out.
+func (b *BotController) exportRecording(r *voiceRecording, tracks map[string]*recording.Track, elapsed time.Duration) ([]recordedFile, error) {
+	var raws, mixed, mixedUsers []string
+	var files []recordedFile
+	defer func() {
+		for _, raw := range raws {
+			os.Remove(raw)
+		}
+	}()
+
+	names := make(map[string]string, len(tracks))
+	users := make([]string, 0, len(tracks))
+	for userID := range tracks {
+		names[userID] = b.memberName(r.guildID, userID)
+		users = append(users, userID)
+	}
+	slices.SortFunc(users, func(a, b string) int { return strings.Compare(names[a], names[b]) })
+
+	var errs []error
+	used := map[string]bool{"mix": true}
+	for _, userID := range users {
+		track := tracks[userID]
+		raws = append(raws, track.Path)
+		if !r.optedIn(userID) {
+			track.Close()
+			continue
+		}
+		if err := errors.Join(track.Pad(elapsed), track.Close()); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		mixed = append(mixed, track.Path)
+		mixedUsers = append(mixedUsers, userID)
+		dst := filepath.Join(r.dir, fileName(names[userID], userID, used)+"."+string(r.format))
+		if err := recording.Export(track.Path, dst, r.format); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		files = append(files, recordedFile{path: dst, users: []string{userID}})
+	}
+
+	if r.mix && len(mixed) > 0 {
+		raw := filepath.Join(r.dir, "mix.pcm")
+		dst := filepath.Join(r.dir, "mix."+string(r.format))
+		err := recording.Mix(raw, mixed...)
+		raws = append(raws, raw)
+		if err == nil {
+			err = recording.Export(raw, dst, r.format)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			files = append([]recordedFile{{path: dst, users: mixedUsers}}, files...)
+		}
+	}
+	return files, errors.Join(errs...)
+}
+
+// postRecordingFiles uploads as many of the files as fit within the guild's
+// upload limit, and links to the rest.
+func (b *BotController) postRecordingFiles(r *voiceRecording, paths []string) {
+	limit := b.uploadLimit(r.guildID)
+	var files []*discordgo.File
+	var total int64
+	var links []string
+	defer func() {
+		for _, f := range files {
+			f.Reader.(*os.File).Close()
+		}
+	}()
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			log.Printf("Error reading recording %s: %v", path, err)
+			continue
+		}
+		if len(files) < maxAttachments && total+info.Size() <= limit {
+			f, err := os.Open(path)
+			if err == nil {
+				files = append(files, &discordgo.File{Name: filepath.Base(path), ContentType: contentType(r.format), Reader: f})
+				total += info.Size()
+				continue
+			}
+		}
+		links = append(links, fmt.Sprintf("• `%s` (%.1f MB): %s", filepath.Base(path), float64(info.Size())/(1<<20), b.recordingLink(path)))
+	}
+
+	content := fmt.Sprintf("📼 **Recording of <#%s>** from %s", r.voiceChannelID, r.started.Format("Jan 2 15:04"))
+	if len(links) > 0 {
+		content += fmt.Sprintf("\nToo big to upload here (the limit is %d MB):\n%s", limit>>20, strings.Join(links, "\n"))
+	}
+	_, err := b.Session.ChannelMessageSendComplex(r.channelID, &discordgo.MessageSend{
+		Content:         content,
+		Files:           files,
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	if err != nil && len(files) > 0 {
+		log.Printf("Error uploading recording: %v", err)
+		for _, f := range files {
+			path := filepath.Join(r.dir, f.Name)
+			links = append(links, fmt.Sprintf("• `%s`: %s", f.Name, b.recordingLink(path)))
+		}
+		b.postRecordingNotice(r, fmt.Sprintf("📼 **Recording of <#%s>** couldn't be uploaded:\n%s", r.voiceChannelID, strings.Join(links, "\n")))
+	} else if err != nil {
+		log.Printf("Error posting recording: %v", err)
+	}
+}
+
+// recordingLink returns where a recording can be downloaded from, or where
+// it is on the bot's host when the recordings folder isn't served.
+func (b *BotController) recordingLink(path string) string {
+	rel, err := filepath.Rel(b.RecordingsDir, path)
+	if b.RecordingsURL == "" || err != nil {
+		return fmt.Sprintf("saved on the bot's host as `%s`", path)
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.TrimSuffix(b.RecordingsURL, "/") + "/" + strings.Join(parts, "/")
+}
+
+// uploadLimit returns the largest upload a guild accepts, which boosting raises.
+func (b *BotController) uploadLimit(guildID string) int64 {
+	guild, err := b.Session.State.Guild(guildID)
+	if err != nil {
+		return 10 << 20
+	}
+	switch guild.PremiumTier {
+	case discordgo.PremiumTier2:
+		return 50 << 20
+	case discordgo.PremiumTier3:
+		return 100 << 20
+	default:
+		return 10 << 20
+	}
+}
+
+// recordingStatus follows headline with who in the voice channel is and isn't
+// being recorded.
+func (b *BotController) recordingStatus(r *voiceRecording, headline string) string {
+	var in, out []string
+	for _, userID := range b.humanIDsIn(r.guildID, r.voiceChannelID) {
+		name := "**" + b.memberName(r.guildID, userID) + "**"
+		if b.recordingConsent(r, userID) {
+			in = append(in, name)
+		} else {
+			out = append(out, name)
+		}
+	}
+	lines := []string{headline}
+	if len(in) > 0 {
+		lines = append(lines, "🎙 Recording: "+strings.Join(in, ", "))
+	}
+	if len(out) > 0 {
+		lines = append(lines, "🔇 Not recording, they haven't opted in: "+strings.Join(out, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// recordingJoined tells someone who joins the voice channel while it's being
+// recorded whether they are.
+func (b *BotController) recordingJoined(gs *GuildSession, userID string) {
+	r := gs.currentRecording()
+	if r == nil {
+		return
+	}
+	if member, err := b.member(r.guildID, userID); err != nil || member.User == nil || member.User.Bot {
+		return
+	}
+	r.mu.Lock()
+	told := r.told[userID]
+	r.told[userID] = true
+	r.mu.Unlock()
+	if told {
+		return
+	}
+
+	name := b.memberName(r.guildID, userID)
+	if b.recordingConsent(r, userID) {
+		b.postRecordingNotice(r, fmt.Sprintf("🔴 **%s**, <#%s> is being recorded and you've opted in. `!record optout` leaves you out.", name, r.voiceChannelID))
+		return
+	}
+	b.postRecordingNotice(r, fmt.Sprintf("🔴 **%s**, <#%s> is being recorded. You're left out unless you `!record optin`.", name, r.voiceChannelID))
+}
+
+// recordingConsent reports whether a member opted in to being recorded in the
+// recording's guild, remembering the answer for the rest of the recording.
+// Without a store nobody could have opted in.
+func (b *BotController) recordingConsent(r *voiceRecording, userID string) bool {
+	if b.Store == nil {
+		return false
+	}
+	r.mu.Lock()
+	consent, ok := r.consent[userID]
+	r.mu.Unlock()
+	if ok {
+		return consent
+	}
+
+	_, err := storage.NewRepository[storage.RecordingConsent](b.Store, storage.BucketConsent).Get(r.guildID + "/" + userID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Printf("Error loading recording consent for %s: %v", userID, err)
+	}
+	r.mu.Lock()
+	r.consent[userID] = err == nil
+	r.mu.Unlock()
+	return err == nil
+}
+
+// setRecordingConsent opts the author in to or out of being recorded in the
+// guild. Opting out of a recording in progress throws away what was recorded
+// of them so far.
+func (b *BotController) setRecordingConsent(msg *discordgo.MessageCreate, consent bool) {
+	consents := storage.NewRepository[storage.RecordingConsent](b.Store, storage.BucketConsent)
+	key := msg.GuildID + "/" + msg.Author.ID
+	var err error
+	if consent {
+		err = consents.Put(key, storage.RecordingConsent{Given: time.Now()})
+	} else {
+		err = consents.Delete(key)
+	}
+	if err != nil {
+		log.Printf("Error saving recording consent for %s: %v", key, err)
+		b.reply(msg, "⚠ Couldn't save that, please try again.")
+		return
+	}
+
+	gs := b.Sessions.Get(msg.GuildID)
+	dropped, saving, posted := false, false, false
+	for _, r := range gs.recordings() {
+		r.mu.Lock()
+		r.consent[msg.Author.ID] = consent
+		r.told[msg.Author.ID] = false
+		if track, ok := r.tracks[msg.Author.ID]; ok && !consent {
+			track.Close()
+			os.Remove(track.Path)
+			delete(r.tracks, msg.Author.ID)
+			dropped = true
+		}
+		// Once it's stopped, the exporter drops them until it posts the files.
+		if r.tracks == nil && !consent {
+			saving = saving || !r.posted
+			posted = posted || r.posted
+		}
+		r.mu.Unlock()
+	}
+
+	switch {
+	case consent:
+		b.reply(msg, "✅ You've opted in to being recorded in this server. `!record optout` takes it back.")
+	case posted:
+		b.reply(msg, "🔕 You've opted out of being recorded. The recording that just stopped was already posted, so it may still include you.")
+	case saving:
+		b.reply(msg, "🔕 You've opted out of being recorded. The recording that just stopped is still being saved, and you'll be left out of it.")
+	case dropped:
+		b.reply(msg, "🔕 You've opted out of being recorded, and what was recorded of you just now has been deleted.")
+	default:
+		b.reply(msg, "🔕 You've opted out of being recorded in this server.")
+	}
+}
+
+// markRecording puts a red dot in front of the bot's nickname while it
+// records, or puts the old nickname back. The bot needs the Change Nickname
+// permission for it; the announcements say the same either way.
+func (b *BotController) markRecording(r *voiceRecording, on bool) {
+	if !on {
+		if r.renamed {
+			if err := b.Session.GuildMemberNickname(r.guildID, "@me", r.nick); err != nil {
+				log.Printf("Error restoring nickname in guild %s: %v", r.guildID, err)
+			}
+		}
+		return
+	}
+
+	me, err := b.member(r.guildID, b.Session.State.User.ID)
+	if err != nil || me.User == nil {
+		return
+	}
+	display := strings.TrimPrefix(me.DisplayName(), recordingDot)
+	if display == "" {
+		display = me.User.Username
+	}
+	name := []rune(recordingDot + display)
+	if len(name) > 32 { // Discord's nickname limit.
+		name = name[:32]
+	}
+	if err := b.Session.GuildMemberNickname(r.guildID, "@me", string(name)); err != nil {
+		log.Printf("Couldn't mark the bot as recording in guild %s: %v", r.guildID, err)
+		return
+	}
+	r.nick, r.renamed = strings.TrimPrefix(me.Nick, recordingDot), true
+}
+
+// postRecordingNotice posts a message about a recording without pinging anyone.
+func (b *BotController) postRecordingNotice(r *voiceRecording, content string) {
+	_, err := b.Session.ChannelMessageSendComplex(r.channelID, &discordgo.MessageSend{
+		Content:         content,
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	if err != nil {
+		log.Printf("Error posting recording notice: %v", err)
+	}
+}
+
+// fileName turns a member's name into a file name not already in used,
+// falling back to their user ID when nothing of the name is left.
+func fileName(name, userID string, used map[string]bool) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			return unicode.ToLower(r)
+		case unicode.IsSpace(r):
+			return '-'
+		default:
+			return -1
+		}
+	}, name)
+	if name == "" {
+		name = userID
+	}
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = fmt.Sprintf("%s-%d", name, i)
+	}
+	used[unique] = true
+	return unique
+}
+
+// contentType returns the MIME type of a recording format.
+func contentType(format recording.Format) string {
+	if format == recording.WAV {
+		return "audio/wav"
+	}
+	return "audio/ogg"
+}
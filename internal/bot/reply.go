@@ -0,0 +1,90 @@
+package bot
+
+import (
+	"log"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// pendingInteraction tracks a slash command invocation so that replies to the
+// synthetic message built for it go back through the interaction.
+type pendingInteraction struct {
+	interaction *discordgo.Interaction
+	deferred    bool
+	responded   bool
+	mu          sync.Mutex
+}
+
+// reply answers the message that triggered a command. Messages that came from a
+// slash command are answered through the interaction, everything else is sent to
+// the channel the message was posted in.
+func (b *BotController) reply(msg *discordgo.MessageCreate, content string) {
+	b.replyComplex(msg, &discordgo.MessageSend{Content: content})
+}
+
+// replyComplex is like reply but allows embeds and components. Replies never
+// ping anyone unless the caller sets AllowedMentions.
+func (b *BotController) replyComplex(msg *discordgo.MessageCreate, data *discordgo.MessageSend) {
+	if data.AllowedMentions == nil {
+		data.AllowedMentions = &discordgo.MessageAllowedMentions{}
+	}
+	if v, ok := b.interactions.Load(msg.ID); ok {
+		v.(*pendingInteraction).send(b.Session, data)
+		return
+	}
+	if _, err := b.Session.ChannelMessageSendComplex(msg.ChannelID, data); err != nil {
+		log.Printf("Error replying in channel %s: %v", msg.ChannelID, err)
+	}
+}
+
+// send responds to the interaction. The first reply fills in the initial (or
+// deferred) response and any later replies become follow-up messages.
+func (pi *pendingInteraction) send(s *discordgo.Session, data *discordgo.MessageSend) {
+	pi.mu.Lock()
+	defer pi.mu.Unlock()
+
+	var err error
+	switch {
+	case pi.responded:
+		_, err = s.FollowupMessageCreate(pi.interaction, false, &discordgo.WebhookParams{
+			Content:         data.Content,
+			Embeds:          data.Embeds,
+			Components:      data.Components,
+			AllowedMentions: data.AllowedMentions,
+		})
+	case pi.deferred:
+		_, err = s.InteractionResponseEdit(pi.interaction, &discordgo.WebhookEdit{
+			Content:         &data.Content,
+			Embeds:          &data.Embeds,
+			Components:      &data.Components,
+			AllowedMentions: data.AllowedMentions,
+		})
+	default:
+		err = s.InteractionRespond(pi.interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content:         data.Content,
+				Embeds:          data.Embeds,
+				Components:      data.Components,
+				AllowedMentions: data.AllowedMentions,
+			},
+		})
+	}
+	if err != nil {
+		log.Printf("Error replying to interaction %s: %v", pi.interaction.ID, err)
+	}
+	pi.responded = true
+}
+
+// finish makes sure the interaction was answered once the command returns, so
+// Discord doesn't show it as failed.
+func (pi *pendingInteraction) finish(s *discordgo.Session) {
+	pi.mu.Lock()
+	responded := pi.responded
+	pi.mu.Unlock()
+
+	if !responded {
+		pi.send(s, &discordgo.MessageSend{Content: "✅ Done."})
+	}
+}
@@ -0,0 +1,192 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AjStraight619/discord-bot/internal/audio"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// searchResults is how many results the picker offers.
+	searchResults = 5
+	// pickTimeout is how long the requester has to pick a result.
+	pickTimeout = 30 * time.Second
+	// searchTimeout bounds the yt-dlp search itself.
+	searchTimeout = 20 * time.Second
+)
+
+// Search modes for the guild's search setting.
+const (
+	searchPick = "pick" // Show the results and let the requester choose.
+	searchTop  = "top"  // Queue the top result straight away.
+)
+
+// topPrefix marks a !play search as wanting the top result, whatever the
+// guild's search setting.
+const topPrefix = searchTop + ":"
+
+// songPicker is a search waiting for its requester to choose a result.
+type songPicker struct {
+	requester string
+	results   []audio.Result
+	choice    chan int // Receives the picked index, or -1 to cancel.
+}
+
+// searchAndPlay searches YouTube for query and queues the top hit or the one
+// the requester picks, depending on top and the guild's search setting.
+func (b *BotController) searchAndPlay(msg *discordgo.MessageCreate, query string, top bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), searchTimeout)
+	defer cancel()
+
+	log.Println("🔎 Searching YouTube for:", query)
+	results, err := audio.Search(ctx, binPath("yt-dlp.exe"), query, searchResults)
+	if err != nil {
+		log.Println("❌ Error searching YouTube:", err)
+		b.reply(msg, "⚠ Couldn't search YouTube, please try again.")
+		return
+	}
+	if len(results) == 0 {
+		b.reply(msg, fmt.Sprintf("🔎 No results for **%s**.", query))
+		return
+	}
+
+	if top || b.guildSettings(msg.GuildID).SearchMode == searchTop || len(results) == 1 {
+		b.Play([]*Song{songFromResult(results[0], msg.Author.ID)}, msg)
+		return
+	}
+
+	picker := &songPicker{
+		requester: msg.Author.ID,
+		results:   results,
+		choice:    make(chan int, 1),
+	}
+	b.pickers.Store(msg.ID, picker)
+	defer b.pickers.Delete(msg.ID)
+
+	b.replyComplex(msg, pickerMessage(msg.ID, query, results))
+
+	select {
+	case i := <-picker.choice:
+		if i >= 0 {
+			b.Play([]*Song{songFromResult(results[i], msg.Author.ID)}, msg)
+		}
+	case <-time.After(pickTimeout):
+		b.reply(msg, "⌛ No song was picked in time.")
+	}
+}
+
+func songFromResult(r audio.Result, requester string) *Song {
+	return &Song{
+		URL:         r.URL,
+		Title:       r.Title,
+		Uploader:    r.Uploader,
+		Duration:    r.Duration,
+		Thumbnail:   r.Thumbnail,
+		RequestedBy: requester,
+	}
+}
+
+// pickerMessage lists search results with a numbered button for each and a
+// cancel button. Button IDs look like "pick:<picker ID>:<index>".
+func pickerMessage(pickerID, query string, results []audio.Result) *discordgo.MessageSend {
+	var lines []string
+	var buttons []discordgo.MessageComponent
+	for i, r := range results {
+		line := fmt.Sprintf("`%d.` [%s](%s)", i+1, r.Title, r.URL)
+		if r.Duration > 0 {
+			line += fmt.Sprintf(" `[%s]`", formatDuration(r.Duration))
+		}
+		if r.Uploader != "" {
+			line += " — " + r.Uploader
+		}
+		lines = append(lines, line)
+		buttons = append(buttons, discordgo.Button{
+			Label:    strconv.Itoa(i + 1),
+			Style:    discordgo.PrimaryButton,
+			CustomID: fmt.Sprintf("pick:%s:%d", pickerID, i),
+		})
+	}
+	buttons = append(buttons, discordgo.Button{
+		Label:    "Cancel",
+		Style:    discordgo.SecondaryButton,
+		CustomID: fmt.Sprintf("pick:%s:-1", pickerID),
+	})
+
+	return &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{{
+			Title:       fmt.Sprintf("🔎 Results for \"%s\"", query),
+			Description: strings.Join(lines, "\n"),
+			Color:       queueColor,
+			Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Pick a song within %d seconds", int(pickTimeout.Seconds()))},
+		}},
+		Components: []discordgo.MessageComponent{discordgo.ActionsRow{Components: buttons}},
+	}
+}
+
+// handlePick delivers a button press on a search result picker.
+func (b *BotController) handlePick(s *discordgo.Session, i *discordgo.InteractionCreate, payload string) {
+	pickerID, index, _ := strings.Cut(payload, ":")
+	n, err := strconv.Atoi(index)
+	if err != nil {
+		log.Printf("Invalid pick %q: %v", payload, err)
+		return
+	}
+
+	v, ok := b.pickers.Load(pickerID)
+	if !ok {
+		updateComponentMessage(s, i, "⌛ This search has expired.")
+		return
+	}
+	picker := v.(*songPicker)
+
+	if interactionUser(i).ID != picker.requester {
+		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Only the person who searched can pick a song.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		if err != nil {
+			log.Printf("Error answering pick: %v", err)
+		}
+		return
+	}
+	if n >= len(picker.results) {
+		log.Printf("Invalid pick %q", payload)
+		return
+	}
+
+	select {
+	case picker.choice <- n:
+	default: // Already picked.
+	}
+	b.pickers.Delete(pickerID)
+
+	if n < 0 {
+		updateComponentMessage(s, i, "✖ Search cancelled.")
+		return
+	}
+	updateComponentMessage(s, i, fmt.Sprintf("🎵 Picked **%s**.", picker.results[n].Title))
+}
+
+// updateComponentMessage replaces the message a button belongs to with text.
+func updateComponentMessage(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    content,
+			Embeds:     []*discordgo.MessageEmbed{},
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+	if err != nil {
+		log.Printf("Error updating message: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package bot
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// GuildSession holds the voice and music state for a single guild.
+type GuildSession struct {
+	GuildID            string
+	VoiceTextChannelID string // Text channel used for voice/music announcements.
+	VoiceConn          *discordgo.VoiceConnection
+	VoiceChannelID     string // Last voice channel joined, used to resume playback.
+	isBotInChannel     bool
+	voiceMuted         bool // The bot joined muted, as !join does, so it can't play music.
+	queue              Queue
+	player             Player
+	inactivityTimer    *time.Timer
+	aloneTimer         *time.Timer     // Running while no one else is in the bot's voice channel.
+	reconnecting       bool            // Playback is rejoining voice after losing the connection.
+	transcript         *transcription  // Captioning the voice channel, nil when not.
+	receiver           *voiceReceiver  // Decoding what's said in voice while anything subscribes.
+	recording          *voiceRecording // Recording the voice channel, nil when not.
+	savingRecording    *voiceRecording // A stopped recording whose files aren't posted yet.
+
+	mu sync.Mutex // Guards the voice connection, announce channel and timer.
+}
+
+// SessionManager hands out guild sessions keyed by guild ID.
+type SessionManager struct {
+	mu       sync.Mutex
+	sessions map[string]*GuildSession
+}
+
+func NewSessionManager() *SessionManager {
+	return &SessionManager{
+		sessions: make(map[string]*GuildSession),
+	}
+}
+
+// Get returns the session for a guild, creating it on first use.
+func (sm *SessionManager) Get(guildID string) *GuildSession {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	gs, ok := sm.sessions[guildID]
+	if !ok {
+		gs = &GuildSession{GuildID: guildID}
+		sm.sessions[guildID] = gs
+	}
+	return gs
+}
+
+// Lookup returns the session for a guild without creating one.
+func (sm *SessionManager) Lookup(guildID string) (*GuildSession, bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	gs, ok := sm.sessions[guildID]
+	return gs, ok
+}
+
+// All returns every session created so far.
+func (sm *SessionManager) All() []*GuildSession {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sessions := make([]*GuildSession, 0, len(sm.sessions))
+	for _, gs := range sm.sessions {
+		sessions = append(sessions, gs)
+	}
+	return sessions
+}
+
+// Voice returns the session's current voice connection.
+func (gs *GuildSession) Voice() *discordgo.VoiceConnection {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	return gs.VoiceConn
+}
+
+// TextChannel returns the channel used for announcements in this guild.
+func (gs *GuildSession) TextChannel() string {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	return gs.VoiceTextChannelID
+}
+
+// VoiceChannel returns the voice channel the bot last joined in this guild.
+func (gs *GuildSession) VoiceChannel() string {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	return gs.VoiceChannelID
+}
+
+// listening reports whether anything is subscribed to the voice channel, so
+// the bot has to join it undeafened.
+func (gs *GuildSession) listening() bool {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	return gs.receiver != nil
+}
+
+// SetTextChannel records the channel used for announcements in this guild.
+func (gs *GuildSession) SetTextChannel(channelID string) {
+	gs.mu.Lock()
+	gs.VoiceTextChannelID = channelID
+	gs.mu.Unlock()
+}
@@ -0,0 +1,233 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AjStraight619/discord-bot/internal/args"
+	"github.com/AjStraight619/discord-bot/internal/storage"
+)
+
+// Defaults used by guilds that haven't changed a setting.
+const (
+	defaultPrefix      = "!"
+	defaultNewsCountry = "us"
+	defaultVolume      = 100
+)
+
+// guildSetting describes one key of !config: how its value is parsed, how the
+// effective value is shown, and how it's stored.
+type guildSetting struct {
+	spec  args.Spec
+	show  func(b *BotController, guildID string) string
+	set   func(settings *storage.GuildSettings, opts args.Values) error
+	reset func(settings *storage.GuildSettings)
+}
+
+// guildSettingDefs lists the settings in the order !config list shows them.
+var guildSettingDefs = []guildSetting{
+	{
+		spec: args.Spec{Name: "prefix", Description: "Prefix for text commands", Kind: args.String},
+		show: func(b *BotController, guildID string) string { return b.prefixFor(guildID) },
+		set: func(settings *storage.GuildSettings, opts args.Values) error {
+			prefix := opts.String("prefix")
+			if len(prefix) > 3 || strings.ContainsAny(prefix, "\"` ") {
+				return fmt.Errorf("the prefix must be 1-3 characters without spaces or quotes")
+			}
+			settings.Prefix = prefix
+			return nil
+		},
+		reset: func(settings *storage.GuildSettings) { settings.Prefix = "" },
+	},
+	{
+		spec: args.Spec{Name: "timeout", Description: "Minutes of inactivity before leaving voice", Kind: args.Duration, Unit: time.Minute},
+		show: func(b *BotController, guildID string) string {
+			return fmt.Sprintf("%d minutes", int(b.timeoutFor(guildID).Minutes()))
+		},
+		set: func(settings *storage.GuildSettings, opts args.Values) error {
+			timeout := opts.Duration("timeout", 0)
+			if timeout < time.Minute {
+				return fmt.Errorf("the timeout must be at least one minute")
+			}
+			settings.TimeoutMinutes = int(timeout.Minutes())
+			return nil
+		},
+		reset: func(settings *storage.GuildSettings) { settings.TimeoutMinutes = 0 },
+	},
+	{
+		spec: args.Spec{Name: "news-country", Description: "Default country code for !news", Kind: args.String},
+		show: func(b *BotController, guildID string) string { return b.newsCountryFor(guildID) },
+		set: func(settings *storage.GuildSettings, opts args.Values) error {
+			country := strings.ToLower(opts.String("news-country"))
+			if len(country) != 2 || strings.Trim(country, "abcdefghijklmnopqrstuvwxyz") != "" {
+				return fmt.Errorf("the country must be a two letter code, e.g. us")
+			}
+			settings.NewsCountry = country
+			return nil
+		},
+		reset: func(settings *storage.GuildSettings) { settings.NewsCountry = "" },
+	},
+	{
+		spec: args.Spec{Name: "volume", Description: "Music volume in percent", Kind: args.Int, Min: 0, Max: 200},
+		show: func(b *BotController, guildID string) string { return fmt.Sprintf("%d%%", b.volumeFor(guildID)) },
+		set: func(settings *storage.GuildSettings, opts args.Values) error {
+			volume := opts.Int("volume", defaultVolume)
+			settings.Volume = &volume
+			return nil
+		},
+		reset: func(settings *storage.GuildSettings) { settings.Volume = nil },
+	},
+	{
+		spec: args.Spec{Name: "announce-channel", Description: "Channel for now playing and voice announcements", Kind: args.Channel},
+		show: func(b *BotController, guildID string) string {
+			if id := b.guildSettings(guildID).AnnounceChannelID; id != "" {
+				return "<#" + id + ">"
+			}
+			return "the channel the command was used in"
+		},
+		set: func(settings *storage.GuildSettings, opts args.Values) error {
+			settings.AnnounceChannelID = opts.String("announce-channel")
+			return nil
+		},
+		reset: func(settings *storage.GuildSettings) { settings.AnnounceChannelID = "" },
+	},
+	{
+		spec: args.Spec{Name: "search", Description: "Whether !play searches let you pick a result or queue the top hit", Kind: args.Enum, Choices: []string{searchPick, searchTop}},
+		show: func(b *BotController, guildID string) string {
+			if mode := b.guildSettings(guildID).SearchMode; mode != "" {
+				return mode
+			}
+			return searchPick
+		},
+		set: func(settings *storage.GuildSettings, opts args.Values) error {
+			settings.SearchMode = opts.String("search")
+			return nil
+		},
+		reset: func(settings *storage.GuildSettings) { settings.SearchMode = "" },
+	},
+	{
+		spec: args.Spec{Name: "playlist-max", Description: "Most songs queued from one playlist", Kind: args.Int, Min: 1, Max: 500},
+		show: func(b *BotController, guildID string) string {
+			return fmt.Sprintf("%d songs", b.playlistMaxFor(guildID))
+		},
+		set: func(settings *storage.GuildSettings, opts args.Values) error {
+			settings.PlaylistMax = opts.Int("playlist-max", defaultPlaylistMax)
+			return nil
+		},
+		reset: func(settings *storage.GuildSettings) { settings.PlaylistMax = 0 },
+	},
+	{
+		spec: args.Spec{Name: "max-duration", Description: "Longest song that can be queued, in minutes", Kind: args.Duration, Unit: time.Minute},
+		show: func(b *BotController, guildID string) string {
+			if limit := b.maxDurationFor(guildID); limit > 0 {
+				return formatDuration(limit)
+			}
+			return "no limit"
+		},
+		set: func(settings *storage.GuildSettings, opts args.Values) error {
+			limit := opts.Duration("max-duration", 0)
+			if limit < time.Minute {
+				return fmt.Errorf("the limit must be at least one minute")
+			}
+			settings.MaxSongSeconds = int(limit.Seconds())
+			return nil
+		},
+		reset: func(settings *storage.GuildSettings) { settings.MaxSongSeconds = 0 },
+	},
+	{
+		spec: args.Spec{Name: "alone-timeout", Description: "How long to stay in voice after everyone else leaves, in seconds", Kind: args.Duration},
+		show: func(b *BotController, guildID string) string { return formatDuration(b.aloneTimeoutFor(guildID)) },
+		set: func(settings *storage.GuildSettings, opts args.Values) error {
+			timeout := opts.Duration("alone-timeout", 0)
+			if timeout < 5*time.Second || timeout > time.Hour {
+				return fmt.Errorf("the timeout must be between 5 seconds and an hour")
+			}
+			settings.AloneTimeoutSeconds = int(timeout.Seconds())
+			return nil
+		},
+		reset: func(settings *storage.GuildSettings) { settings.AloneTimeoutSeconds = 0 },
+	},
+	{
+		spec: args.Spec{Name: "wake-phrase", Description: "What to say before a voice command", Kind: args.Text},
+		show: func(b *BotController, guildID string) string { return `"` + b.wakePhraseFor(guildID) + `"` },
+		set: func(settings *storage.GuildSettings, opts args.Values) error {
+			words := strings.Fields(strings.ToLower(opts.String("wake-phrase")))
+			plain := len(words) >= 2 && len(words) <= 4
+			for _, word := range words {
+				plain = plain && strings.Trim(word, "abcdefghijklmnopqrstuvwxyz") == ""
+			}
+			if !plain {
+				return fmt.Errorf("the wake phrase must be 2-4 words of plain letters, e.g. hey bot")
+			}
+			settings.WakePhrase = strings.Join(words, " ")
+			return nil
+		},
+		reset: func(settings *storage.GuildSettings) { settings.WakePhrase = "" },
+	},
+}
+
+// lookupGuildSetting finds a setting definition by key.
+func lookupGuildSetting(key string) (guildSetting, bool) {
+	for _, def := range guildSettingDefs {
+		if def.spec.Name == key {
+			return def, true
+		}
+	}
+	return guildSetting{}, false
+}
+
+func guildSettingKeys() []string {
+	keys := make([]string, len(guildSettingDefs))
+	for i, def := range guildSettingDefs {
+		keys[i] = def.spec.Name
+	}
+	return keys
+}
+
+// prefixFor returns the prefix text commands use in a guild.
+func (b *BotController) prefixFor(guildID string) string {
+	if prefix := b.guildSettings(guildID).Prefix; prefix != "" {
+		return prefix
+	}
+	return defaultPrefix
+}
+
+// timeoutFor returns how long the bot stays in voice without activity in a guild.
+func (b *BotController) timeoutFor(guildID string) time.Duration {
+	if minutes := b.guildSettings(guildID).TimeoutMinutes; minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	return b.TimeoutDuration
+}
+
+// newsCountryFor returns the country !news uses when none is given.
+func (b *BotController) newsCountryFor(guildID string) string {
+	if country := b.guildSettings(guildID).NewsCountry; country != "" {
+		return country
+	}
+	return defaultNewsCountry
+}
+
+// volumeFor returns the guild's music volume in percent.
+func (b *BotController) volumeFor(guildID string) int {
+	if volume := b.guildSettings(guildID).Volume; volume != nil {
+		return *volume
+	}
+	return defaultVolume
+}
+
+// maxDurationFor returns the longest song a guild lets users queue, or zero
+// when there's no limit.
+func (b *BotController) maxDurationFor(guildID string) time.Duration {
+	return time.Duration(b.guildSettings(guildID).MaxSongSeconds) * time.Second
+}
+
+// announceChannel returns where background messages such as "Now playing" go:
+// the guild's announcement channel, or the channel the bot was last used in.
+func (b *BotController) announceChannel(gs *GuildSession) string {
+	if id := b.guildSettings(gs.GuildID).AnnounceChannelID; id != "" {
+		return id
+	}
+	return gs.TextChannel()
+}
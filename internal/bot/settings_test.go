@@ -0,0 +1,136 @@
+package bot
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AjStraight619/discord-bot/internal/storage"
+)
+
+// newSettingsBot returns a controller with an empty store for guild settings.
+func newSettingsBot(t *testing.T) *BotController {
+	t.Helper()
+	store, err := storage.Open(filepath.Join(t.TempDir(), "bot.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return &BotController{Store: store, TimeoutDuration: 10 * time.Minute}
+}
+
+func TestGuildSettingsSet(t *testing.T) {
+	tests := []struct {
+		key, value string
+		want       string // How the setting shows once set, empty when refused.
+	}{
+		{"prefix", "?", "?"},
+		{"prefix", "$$$", "$$$"},
+		{"prefix", "!!!!", ""},
+		{"prefix", "`", ""},
+		{"prefix", `"a b"`, ""},
+		{"timeout", "15", "15 minutes"},
+		{"timeout", "2h", "120 minutes"},
+		{"timeout", "0", ""},
+		{"timeout", "30s", ""},
+		{"news-country", "GB", "gb"},
+		{"news-country", "usa", ""},
+		{"news-country", "u1", ""},
+		{"volume", "55", "55%"},
+		{"volume", "0", "0%"},
+		{"volume", "200", "200%"},
+		{"volume", "201", ""},
+		{"volume", "-1", ""},
+		{"volume", "loud", ""},
+		{"announce-channel", "<#123>", "<#123>"},
+		{"announce-channel", "123", ""},
+		{"announce-channel", "general", ""},
+		{"search", "top", "top"},
+		{"search", "best", ""},
+		{"playlist-max", "50", "50 songs"},
+		{"playlist-max", "0", ""},
+		{"playlist-max", "501", ""},
+		{"max-duration", "10", "10:00"},
+		{"max-duration", "1h30m", "1:30:00"},
+		{"max-duration", "0", ""},
+		{"alone-timeout", "30", "0:30"},
+		{"alone-timeout", "1h", "1:00:00"},
+		{"alone-timeout", "4", ""},
+		{"alone-timeout", "61m", ""},
+		{"wake-phrase", "Hey DJ", `"hey dj"`},
+		{"wake-phrase", "ok music bot", `"ok music bot"`},
+		{"wake-phrase", "bot", ""},
+		{"wake-phrase", "hey bot 2", ""},
+		{"wake-phrase", "hey there music bot now", ""},
+	}
+	b := newSettingsBot(t)
+	for i, tt := range tests {
+		guildID := fmt.Sprint(i)
+		def, ok := lookupGuildSetting(tt.key)
+		if !ok {
+			t.Fatalf("no setting %q", tt.key)
+		}
+		before := def.show(b, guildID)
+
+		settings := b.guildSettings(guildID)
+		err := applyGuildSetting(def, &settings, tt.value)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("set %s %q was accepted, want it refused", tt.key, tt.value)
+			}
+			if got := def.show(b, guildID); got != before {
+				t.Errorf("refused set %s %q changed it from %q to %q", tt.key, tt.value, before, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("set %s %q: %v", tt.key, tt.value, err)
+			continue
+		}
+		if err := b.updateGuildSettings(guildID, func(s *storage.GuildSettings) { *s = settings }); err != nil {
+			t.Fatal(err)
+		}
+		if got := def.show(b, guildID); got != tt.want {
+			t.Errorf("set %s %q shows %q, want %q", tt.key, tt.value, got, tt.want)
+		}
+
+		if err := b.updateGuildSettings(guildID, def.reset); err != nil {
+			t.Fatal(err)
+		}
+		if got := def.show(b, guildID); got != before {
+			t.Errorf("reset %s shows %q, want the default %q", tt.key, got, before)
+		}
+	}
+}
+
+func TestGuildSettingsList(t *testing.T) {
+	b := newSettingsBot(t)
+	settings := b.guildSettings("g1")
+	for key, value := range map[string]string{"volume": "80", "prefix": "?"} {
+		def, _ := lookupGuildSetting(key)
+		if err := applyGuildSetting(def, &settings, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.updateGuildSettings("g1", func(s *storage.GuildSettings) { *s = settings }); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(b.formatGuildSettings("g1")), "\n")[1:]
+	if len(lines) != len(guildSettingDefs) {
+		t.Fatalf("listed %d settings, want %d", len(lines), len(guildSettingDefs))
+	}
+	for i, def := range guildSettingDefs {
+		want := fmt.Sprintf("`%s`: %s — ", def.spec.Name, def.show(b, "g1"))
+		if !strings.HasPrefix(lines[i], want) {
+			t.Errorf("line %d is %q, want it to start with %q", i+1, lines[i], want)
+		}
+	}
+	for _, want := range []string{"`volume`: 80%", "`prefix`: ?", "`timeout`: 10 minutes"} {
+		if !strings.Contains(strings.Join(lines, "\n"), want) {
+			t.Errorf("list doesn't have %q", want)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/AjStraight619/discord-bot/internal/args"
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxSlashDescription is the longest description Discord accepts for a command
+// or an option. A longer one fails the whole bulk registration.
+const maxSlashDescription = 100
+
+// DeferredCommand is implemented by commands that can take longer than the
+// three seconds Discord allows before an interaction must be acknowledged.
+type DeferredCommand interface {
+	Deferred() bool
+}
+
+// RegisterSlashCommands registers an application command for every command in
+// the registry so they show up with autocomplete in Discord.
+func (b *BotController) RegisterSlashCommands() error {
+	var appCmds []*discordgo.ApplicationCommand
+	for _, name := range b.CommandRegistry.Names() {
+		cmd, _ := b.CommandRegistry.Get(name)
+		appCmds = append(appCmds, slashCommand(name, cmd))
+	}
+
+	_, err := b.Session.ApplicationCommandBulkOverwrite(b.Session.State.User.ID, "", appCmds)
+	if err != nil {
+		return fmt.Errorf("failed to register slash commands: %w", err)
+	}
+	log.Printf("Registered %d slash commands", len(appCmds))
+	return nil
+}
+
+// InteractionHandler routes slash command invocations to the matching command
+// and button presses to their component handlers.
+func (b *BotController) InteractionHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		b.handleSlashCommand(s, i)
+	case discordgo.InteractionMessageComponent:
+		b.handleComponent(s, i)
+	}
+}
+
+func (b *BotController) handleSlashCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	data := i.ApplicationCommandData()
+	name := "!" + data.Name
+	cmd, ok := b.CommandRegistry.Get(name)
+	if !ok {
+		log.Printf("Unknown slash command: %s", data.Name)
+		return
+	}
+
+	pi := &pendingInteraction{interaction: i.Interaction}
+	if ok, reason := b.authorize(i.GuildID, i.ChannelID, interactionUser(i).ID, name, cmd); !ok {
+		pi.send(s, &discordgo.MessageSend{Content: reason})
+		return
+	}
+
+	if dc, ok := cmd.(DeferredCommand); ok && dc.Deferred() {
+		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		})
+		if err != nil {
+			log.Printf("Error deferring interaction for %s: %v", name, err)
+			return
+		}
+		pi.deferred = true
+	}
+
+	raw := slashOptions(cmd.Args(), data.Options)
+	msg := interactionMessage(i, name, cmd.Args(), raw)
+
+	b.interactions.Store(msg.ID, pi)
+	defer b.interactions.Delete(msg.ID)
+
+	if msg.GuildID != "" {
+		b.Sessions.Get(msg.GuildID).SetTextChannel(msg.ChannelID)
+		b.ResetTimeout(msg.GuildID)
+	}
+
+	opts, err := args.ParseNamed(cmd.Args(), raw)
+	if err != nil {
+		pi.send(s, &discordgo.MessageSend{Content: usageError(name, cmd, err)})
+		return
+	}
+
+	cmd.Execute(b, msg, opts)
+	pi.finish(s)
+}
+
+// slashCommand describes a registry command as a Discord application command.
+func slashCommand(name string, cmd Command) *discordgo.ApplicationCommand {
+	var options []*discordgo.ApplicationCommandOption
+	for _, spec := range cmd.Args() {
+		option := &discordgo.ApplicationCommandOption{
+			Type:        slashOptionType(spec.Kind),
+			Name:        spec.Name,
+			Description: slashDescription(spec.Description),
+			Required:    spec.Required,
+		}
+		if spec.Kind == args.Int && (spec.Min != 0 || spec.Max != 0) {
+			min := float64(spec.Min)
+			option.MinValue = &min
+			option.MaxValue = float64(spec.Max)
+		}
+		// A variadic option is typed out as words, so it can take several choices.
+		if !spec.Variadic {
+			for _, choice := range spec.Choices {
+				option.Choices = append(option.Choices, &discordgo.ApplicationCommandOptionChoice{Name: choice, Value: choice})
+			}
+		}
+		options = append(options, option)
+	}
+
+	return &discordgo.ApplicationCommand{
+		Name:        strings.TrimPrefix(name, "!"),
+		Description: slashDescription(cmd.Help()),
+		Options:     options,
+	}
+}
+
+// slashDescription cuts a description down to what Discord accepts.
+func slashDescription(description string) string {
+	runes := []rune(description)
+	if len(runes) <= maxSlashDescription {
+		return description
+	}
+	return string(runes[:maxSlashDescription-1]) + "…"
+}
+
+func slashOptionType(kind args.Kind) discordgo.ApplicationCommandOptionType {
+	switch kind {
+	case args.Int:
+		return discordgo.ApplicationCommandOptionInteger
+	case args.User:
+		return discordgo.ApplicationCommandOptionUser
+	case args.Channel:
+		return discordgo.ApplicationCommandOptionChannel
+	case args.Role:
+		return discordgo.ApplicationCommandOptionRole
+	default:
+		return discordgo.ApplicationCommandOptionString
+	}
+}
+
+// slashOptions collects the words for each option by argument name so they can
+// be parsed the same way as a text command. Variadic and free text options are
+// split into words, everything else is kept whole.
+func slashOptions(specs []args.Spec, data []*discordgo.ApplicationCommandInteractionDataOption) map[string][]string {
+	byName := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(data))
+	for _, opt := range data {
+		byName[opt.Name] = opt
+	}
+
+	raw := make(map[string][]string, len(specs))
+	for _, spec := range specs {
+		opt, ok := byName[spec.Name]
+		if !ok {
+			continue
+		}
+		switch {
+		case opt.Type == discordgo.ApplicationCommandOptionInteger:
+			raw[spec.Name] = []string{strconv.FormatInt(opt.IntValue(), 10)}
+		case opt.Type == discordgo.ApplicationCommandOptionString && (spec.Variadic || spec.Kind == args.Text):
+			raw[spec.Name] = strings.Fields(opt.StringValue())
+		default:
+			raw[spec.Name] = []string{fmt.Sprint(opt.Value)}
+		}
+	}
+	return raw
+}
+
+// interactionMessage builds the message a text command would have received for
+// this interaction so commands can share a single Execute path.
+func interactionMessage(i *discordgo.InteractionCreate, name string, specs []args.Spec, raw map[string][]string) *discordgo.MessageCreate {
+	var words []string
+	for _, spec := range specs {
+		words = append(words, raw[spec.Name]...)
+	}
+
+	return &discordgo.MessageCreate{
+		Message: &discordgo.Message{
+			ID:        i.ID,
+			ChannelID: i.ChannelID,
+			GuildID:   i.GuildID,
+			Author:    interactionUser(i),
+			Member:    i.Member,
+			Content:   strings.TrimSpace(name + " " + strings.Join(words, " ")),
+		},
+	}
+}
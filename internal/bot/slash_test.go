@@ -0,0 +1,68 @@
+package bot
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// slashName is the form Discord accepts for command and option names.
+var slashName = regexp.MustCompile(`^[-_\p{Ll}\p{N}]{1,32}$`)
+
+func TestSlashCommandsRegister(t *testing.T) {
+	b := &BotController{}
+	b.InitCommands()
+
+	for _, name := range b.CommandRegistry.Names() {
+		cmd, _ := b.CommandRegistry.Get(name)
+		// Descriptions are cut to fit, but shouldn't need to be.
+		if n := utf8.RuneCountInString(cmd.Help()); n > maxSlashDescription {
+			t.Errorf("%s help is %d characters, the most is %d", name, n, maxSlashDescription)
+		}
+		for _, spec := range cmd.Args() {
+			if n := utf8.RuneCountInString(spec.Description); n > maxSlashDescription {
+				t.Errorf("%s %s description is %d characters, the most is %d", name, spec.Name, n, maxSlashDescription)
+			}
+		}
+
+		appCmd := slashCommand(name, cmd)
+		if !slashName.MatchString(appCmd.Name) {
+			t.Errorf("%s: invalid command name %q", name, appCmd.Name)
+		}
+		if n := utf8.RuneCountInString(appCmd.Description); n == 0 || n > maxSlashDescription {
+			t.Errorf("%s: description is %d characters", name, n)
+		}
+		if len(appCmd.Options) > 25 {
+			t.Errorf("%s: %d options, the most is 25", name, len(appCmd.Options))
+		}
+		optional := false
+		for _, option := range appCmd.Options {
+			if !slashName.MatchString(option.Name) {
+				t.Errorf("%s: invalid option name %q", name, option.Name)
+			}
+			if n := utf8.RuneCountInString(option.Description); n == 0 || n > maxSlashDescription {
+				t.Errorf("%s %s: description is %d characters", name, option.Name, n)
+			}
+			if len(option.Choices) > 25 {
+				t.Errorf("%s %s: %d choices, the most is 25", name, option.Name, len(option.Choices))
+			}
+			if option.Required && optional {
+				t.Errorf("%s %s: required option after an optional one", name, option.Name)
+			}
+			optional = optional || !option.Required
+		}
+	}
+}
+
+func TestSlashDescription(t *testing.T) {
+	short := "Plays a song."
+	if got := slashDescription(short); got != short {
+		t.Errorf("slashDescription(%q) = %q, want it unchanged", short, got)
+	}
+	long := strings.Repeat("é", maxSlashDescription+1)
+	got := slashDescription(long)
+	if n := utf8.RuneCountInString(got); n != maxSlashDescription || !strings.HasSuffix(got, "…") {
+		t.Errorf("slashDescription of %d characters = %d characters %q, want %d ending in …", maxSlashDescription+1, n, got, maxSlashDescription)
+	}
+}
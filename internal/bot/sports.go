@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/AjStraight619/discord-bot/internal/args"
 	"github.com/bwmarrin/discordgo"
 )
 
@@ -25,22 +26,16 @@ type Team struct {
 // SportsCommand defines the sports command.
 type SportsCommand struct{}
 
-func (sc SportsCommand) Execute(b *BotController, msg *discordgo.MessageCreate, options []string) {
-	// Validate that at least a team and a player name are provided.
-	if len(options) < 2 {
-		b.displayCmdError(msg.ChannelID, "⚠ Usage: `!sports <team> <player> [season]`")
-		return
-	}
-
+func (sc SportsCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
 	// Load teams data from file.
 	teams := LoadTeams()
 	if teams == nil {
-		b.displayCmdError(msg.ChannelID, "⚠ Error loading team data.")
+		b.reply(msg, "⚠ Error loading team data.")
 		return
 	}
 
 	// Create a SportsQuery using the options and loaded teams.
-	// query, err := NewSportsQuery(options, teams)
+	// query, err := NewSportsQuery(opts, teams)
 	// if err != nil {
 	// 	b.displayCmdError(msg.ChannelID, fmt.Sprintf("⚠ %s", err.Error()))
 	// 	return
@@ -67,9 +62,25 @@ func (sc SportsCommand) Execute(b *BotController, msg *discordgo.MessageCreate,
 }
 
 func (sc SportsCommand) Help() string {
-	return "!sports <team> <player> [season] - Query for NBA stats. Example: !sports Lakers LeBron James 2024"
+	return "Query for NBA stats. Example: !sports Lakers \"LeBron James\" 2024"
+}
+
+func (sc SportsCommand) Args() []args.Spec {
+	return []args.Spec{
+		{Name: "team", Description: "Team name, e.g. Lakers", Kind: args.String, Required: true},
+		{Name: "player", Description: "Player name", Kind: args.String, Required: true},
+		{Name: "season", Description: "Season year, e.g. 2024", Kind: args.String},
+	}
 }
 
+func (sc SportsCommand) Category() string { return "Information" }
+
+func (sc SportsCommand) Examples() []string {
+	return []string{"!sports Lakers \"LeBron James\"", "!sports Lakers \"LeBron James\" 2024"}
+}
+
+func (sc SportsCommand) Deferred() bool { return true }
+
 // LoadTeams loads the teams from a JSON file.
 func LoadTeams() *Teams {
 	// Adjust the path as needed. Here, we assume the data file is at the project root in a 'data' folder.
@@ -117,11 +128,8 @@ type SportsQuery struct {
 	Season     string
 }
 
-func NewSportsQuery(options []string, teams *Teams) (*SportsQuery, error) {
-	teamQuery := options[0]
-	playerQuery := options[1]
-
-	team, err := teams.FindTeam(teamQuery)
+func NewSportsQuery(opts args.Values, teams *Teams) (*SportsQuery, error) {
+	team, err := teams.FindTeam(opts.String("team"))
 	if err != nil {
 		return nil, err
 	}
@@ -129,10 +137,8 @@ func NewSportsQuery(options []string, teams *Teams) (*SportsQuery, error) {
 	query := &SportsQuery{
 		TeamID:     team.ID,
 		TeamName:   team.Name,
-		PlayerName: playerQuery,
-	}
-	if len(options) >= 3 {
-		query.Season = options[2]
+		PlayerName: opts.String("player"),
+		Season:     opts.String("season"),
 	}
 	return query, nil
 }
@@ -0,0 +1,155 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/AjStraight619/discord-bot/internal/storage"
+)
+
+// guildSettings loads a guild's saved settings, returning the zero value when
+// nothing is saved or no store is configured.
+func (b *BotController) guildSettings(guildID string) storage.GuildSettings {
+	if b.Store == nil {
+		return storage.GuildSettings{}
+	}
+	settings, err := storage.NewRepository[storage.GuildSettings](b.Store, storage.BucketGuildSettings).Get(guildID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Printf("Error loading settings for guild %s: %v", guildID, err)
+	}
+	return settings
+}
+
+// updateGuildSettings applies fn to a guild's settings and saves the result.
+func (b *BotController) updateGuildSettings(guildID string, fn func(settings *storage.GuildSettings)) error {
+	if b.Store == nil {
+		return fmt.Errorf("no store configured")
+	}
+	settings := b.guildSettings(guildID)
+	fn(&settings)
+	return storage.NewRepository[storage.GuildSettings](b.Store, storage.BucketGuildSettings).Put(guildID, settings)
+}
+
+// saveQueue persists the guild's current and queued songs, and the voice
+// channel the bot is in, so they survive a restart. The saved record is removed
+// once the queue is empty and the bot has left voice.
+func (b *BotController) saveQueue(gs *GuildSession) {
+	if b.Store == nil {
+		return
+	}
+	queues := storage.NewRepository[storage.QueueState](b.Store, storage.BucketQueues)
+
+	songs, _ := b.queuedSongs(gs)
+	var err error
+	if len(songs) == 0 && gs.Voice() == nil {
+		err = queues.Delete(gs.GuildID)
+	} else {
+		state := storage.QueueState{
+			TextChannelID:  gs.TextChannel(),
+			VoiceChannelID: gs.VoiceChannel(),
+			Songs:          songs,
+		}
+		current := gs.queue.Current()
+		// Positions only apply to the first song when it's saved, not an upload.
+		if song, elapsed, _, _ := gs.player.Status(); song != nil && song == current && !song.upload {
+			state.Position = elapsed.Seconds()
+		} else if upcoming := gs.queue.Songs(); current == nil && len(upcoming) > 0 && !upcoming[0].upload {
+			// A song put back after losing voice remembers where it stopped.
+			state.Position = upcoming[0].resumeAt.Seconds()
+		}
+		err = queues.Put(gs.GuildID, state)
+	}
+	if err != nil {
+		log.Printf("Error saving queue for guild %s: %v", gs.GuildID, err)
+	}
+}
+
+func queuedSong(song *Song) storage.QueuedSong {
+	return storage.QueuedSong{
+		URL:         song.URL,
+		File:        song.File,
+		Title:       song.Title,
+		Uploader:    song.Uploader,
+		Seconds:     song.Duration.Seconds(),
+		Thumbnail:   song.Thumbnail,
+		RequestedBy: song.RequestedBy,
+	}
+}
+
+func songFromQueued(saved storage.QueuedSong) *Song {
+	return &Song{
+		URL:         saved.URL,
+		File:        saved.File,
+		Title:       saved.Title,
+		Uploader:    saved.Uploader,
+		Duration:    time.Duration(saved.Seconds * float64(time.Second)),
+		Thumbnail:   saved.Thumbnail,
+		RequestedBy: saved.RequestedBy,
+	}
+}
+
+// SaveQueues saves every guild's queue along with how far into the current
+// song playback is, so a restart can pick up from there.
+func (b *BotController) SaveQueues() {
+	for _, gs := range b.Sessions.All() {
+		b.saveQueue(gs)
+	}
+}
+
+// RestoreQueues rejoins the voice channel the bot was in before it last
+// stopped and resumes playback of the queue saved there.
+func (b *BotController) RestoreQueues() {
+	if b.Store == nil {
+		return
+	}
+
+	states, err := storage.NewRepository[storage.QueueState](b.Store, storage.BucketQueues).All()
+	if err != nil {
+		log.Printf("Error loading saved queues: %v", err)
+		return
+	}
+
+	for guildID, state := range states {
+		songs := state.Songs
+		for _, url := range state.URLs {
+			songs = append(songs, storage.QueuedSong{URL: url})
+		}
+		if state.VoiceChannelID == "" {
+			continue
+		}
+
+		gs := b.Sessions.Get(guildID)
+		gs.SetTextChannel(state.TextChannelID)
+		gs.mu.Lock()
+		gs.VoiceChannelID = state.VoiceChannelID
+		gs.mu.Unlock()
+
+		if len(songs) == 0 {
+			log.Printf("🔁 Rejoining voice channel %s in guild %s", state.VoiceChannelID, guildID)
+			if _, err := b.joinChannel(guildID, state.VoiceChannelID, false, true); err != nil {
+				log.Printf("❌ Error rejoining voice in guild %s: %v", guildID, err)
+				b.saveQueue(gs)
+				continue
+			}
+			b.ResetTimeout(guildID)
+			continue
+		}
+
+		for i, saved := range songs {
+			song := songFromQueued(saved)
+			if i == 0 {
+				song.resumeAt = time.Duration(state.Position * float64(time.Second))
+			}
+			gs.queue.Add(song)
+		}
+
+		log.Printf("🔁 Restoring %d songs for guild %s", len(songs), guildID)
+		b.Session.ChannelMessageSend(b.announceChannel(gs), fmt.Sprintf("🔁 Resuming %d queued song(s) after a restart.", len(songs)))
+		b.ResetTimeout(guildID)
+		if gs.queue.StartPlaying() {
+			go b.startPlaying(gs, "")
+		}
+	}
+}
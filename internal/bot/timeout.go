@@ -3,49 +3,68 @@ package bot
 import (
 	"fmt"
 	"log"
-	"strconv"
 	"time"
 
+	"github.com/AjStraight619/discord-bot/internal/args"
+	"github.com/AjStraight619/discord-bot/internal/storage"
 	"github.com/bwmarrin/discordgo"
 )
 
 type TimeoutCommand struct{}
 
-func (tc TimeoutCommand) Execute(b *BotController, msg *discordgo.MessageCreate, options []string) {
-	if len(options) != 1 {
-		b.displayCmdError(msg.ChannelID, "Please specify a timeout duration: !timeout 30 (This will set a timeout for 30 minutes) defaults to 20 minutes")
+func (tc TimeoutCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	timeout := opts.Duration("minutes", 20*time.Minute)
+	if timeout < time.Minute {
+		b.reply(msg, "⚠ The timeout must be at least one minute.")
 		return
 	}
 
-	timeout := options[0]
-	num, err := strconv.Atoi(timeout)
-
+	err := b.updateGuildSettings(msg.GuildID, func(settings *storage.GuildSettings) {
+		settings.TimeoutMinutes = int(timeout.Minutes())
+	})
 	if err != nil {
-		b.displayCmdError(msg.ChannelID, "Please input a number")
+		log.Printf("Error saving timeout for guild %s: %v", msg.GuildID, err)
+		b.reply(msg, "⚠ Couldn't save the timeout, please try again.")
 		return
 	}
+	b.reply(msg, fmt.Sprintf("Timeout duration set to %d minutes.", int(timeout.Minutes())))
+}
 
-	b.TimeoutDuration = time.Duration(num) * time.Minute
-	b.Session.ChannelMessageSend(msg.ChannelID, fmt.Sprintf("Timeout duration set to %d minutes.", num))
+func (tc TimeoutCommand) Help() string {
+	return "Set a timeout for the bot that will trigger it to leave on inactivity"
+}
 
+func (tc TimeoutCommand) Args() []args.Spec {
+	return []args.Spec{
+		{Name: "minutes", Description: "Minutes of inactivity before leaving voice, e.g. 30 or 1h", Kind: args.Duration, Unit: time.Minute, Required: true},
+	}
 }
 
-func (tc TimeoutCommand) Help() string {
-	return "!timeout - Set a timeout for the bot that will trigger it to leave on inactivity"
+func (tc TimeoutCommand) Permission() int64 { return discordgo.PermissionVoiceMoveMembers }
+
+func (tc TimeoutCommand) Category() string { return "Voice" }
+
+func (tc TimeoutCommand) Examples() []string {
+	return []string{"!timeout 30", "!timeout 1h"}
 }
 
-func (b *BotController) ResetTimeout() {
-	log.Println("ResetTimeout: Called to restart the inactivity timer.")
+func (b *BotController) ResetTimeout(guildID string) {
+	log.Printf("ResetTimeout: Called to restart the inactivity timer for guild %s.", guildID)
+
+	timeout := b.timeoutFor(guildID)
+	gs := b.Sessions.Get(guildID)
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
 
 	// If there's an existing timer, stop it.
-	if b.inactivityTimer != nil {
+	if gs.inactivityTimer != nil {
 		log.Println("ResetTimeout: An existing timer was found; attempting to stop it.")
 		// Stop returns false if the timer has already expired.
-		if !b.inactivityTimer.Stop() {
+		if !gs.inactivityTimer.Stop() {
 			log.Println("ResetTimeout: Timer had already expired; draining the timer's channel.")
 			// Drain the timer's channel if needed.
 			select {
-			case <-b.inactivityTimer.C:
+			case <-gs.inactivityTimer.C:
 				log.Println("ResetTimeout: Drained one value from the timer's channel.")
 			default:
 				log.Println("ResetTimeout: No value to drain from the timer's channel.")
@@ -58,15 +77,29 @@ func (b *BotController) ResetTimeout() {
 	}
 
 	// Start a new timer with the configured duration.
-	b.inactivityTimer = time.AfterFunc(b.TimeoutDuration, func() {
+	gs.inactivityTimer = time.AfterFunc(timeout, func() {
 		log.Println("Timeout reached. Executing timeout action.")
-		b.OnTimeout()
+		b.OnTimeout(guildID)
 	})
 
-	log.Printf("ResetTimeout: New timer started with a timeout duration of %v.\n", b.TimeoutDuration)
+	log.Printf("ResetTimeout: New timer started with a timeout duration of %v.\n", timeout)
 }
 
-func (b *BotController) OnTimeout() {
-	log.Println("Timeout reached (inactivity)")
-	b.LeaveVoiceChannel()
+func (b *BotController) OnTimeout(guildID string) {
+	// Playing music counts as activity, so wait for it to finish or be paused.
+	// So does recording, which often runs for hours without a command.
+	if gs, ok := b.Sessions.Lookup(guildID); ok {
+		if song, _, _, paused := gs.player.Status(); song != nil && !paused {
+			log.Printf("Timeout reached in guild %s while music is playing, waiting longer", guildID)
+			b.ResetTimeout(guildID)
+			return
+		}
+		if gs.currentRecording() != nil {
+			log.Printf("Timeout reached in guild %s while recording, waiting longer", guildID)
+			b.ResetTimeout(guildID)
+			return
+		}
+	}
+	log.Printf("Timeout reached (inactivity) in guild %s", guildID)
+	b.LeaveVoiceChannel(guildID, leftInactive)
 }
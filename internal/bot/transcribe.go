@@ -0,0 +1,291 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AjStraight619/discord-bot/internal/args"
+	"github.com/AjStraight619/discord-bot/internal/receive"
+	"github.com/AjStraight619/discord-bot/internal/speech"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// speakerIdle is how long someone is quiet before their recognition stream
+	// is closed, which makes the engine finish their sentence.
+	speakerIdle = 2 * time.Second
+	// speakerBuffer is how many 20ms packets of someone's speech may wait on a
+	// slow engine before their audio is dropped.
+	speakerBuffer = 50
+	// captionGrace bounds waiting for the last captions of a closed stream.
+	captionGrace = 10 * time.Second
+)
+
+// transcription listens to a guild's voice channel, running voice commands and
+// captioning what's said in a text channel.
+type transcription struct {
+	guildID   string
+	channelID string
+	cancel    context.CancelFunc
+	done      chan struct{} // Closed once the last captions are posted.
+	captions  bool          // Post what's said, not just act on voice commands.
+}
+
+// speaker is one person's audio on its way to the recognizer.
+type speaker struct {
+	audio chan []int16 // Nil while they're quiet.
+	heard time.Time
+}
+
+// TranscribeCommand captions what's said in the bot's voice channel, or just
+// listens for voice commands.
+type TranscribeCommand struct{}
+
+func (tc TranscribeCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	switch opts.String("action") {
+	case "start":
+		b.startTranscription(msg, true)
+	case "commands":
+		b.startTranscription(msg, false)
+	default:
+		if !b.stopTranscription(b.Sessions.Get(msg.GuildID)) {
+			b.reply(msg, "⚠ I'm not listening to the voice channel.")
+			return
+		}
+		b.reply(msg, "🛑 Stopped listening to the voice channel.")
+	}
+}
+
+func (tc TranscribeCommand) Help() string {
+	return "Posts live captions of what's said in the voice channel and runs spoken commands."
+}
+
+func (tc TranscribeCommand) Args() []args.Spec {
+	return []args.Spec{
+		{Name: "action", Description: "Start captioning, only listen for voice commands, or stop", Kind: args.Enum, Required: true, Choices: []string{"start", "commands", "stop"}},
+	}
+}
+
+func (tc TranscribeCommand) Category() string { return "Voice" }
+
+func (tc TranscribeCommand) Examples() []string {
+	return []string{"!transcribe start", "!transcribe commands", "!transcribe stop"}
+}
+
+// startTranscription starts listening to the bot's voice channel, or the
+// requester's if the bot isn't in one, posting to the channel the command came
+// from. Captions are only posted when captions is set; voice commands always
+// work.
+func (b *BotController) startTranscription(msg *discordgo.MessageCreate, captions bool) {
+	if b.Speech == nil {
+		b.reply(msg, "⚠ Speech-to-text isn't set up on this bot.")
+		return
+	}
+
+	gs := b.Sessions.Get(msg.GuildID)
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &transcription{
+		guildID:   msg.GuildID,
+		channelID: msg.ChannelID,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		captions:  captions,
+	}
+
+	gs.mu.Lock()
+	if gs.transcript != nil {
+		gs.mu.Unlock()
+		cancel()
+		b.reply(msg, "⚠ I'm already listening to the voice channel. Use `!transcribe stop` first.")
+		return
+	}
+	gs.transcript = t
+	gs.mu.Unlock()
+
+	sub, err := b.subscribeVoice(gs, "", msg.Author.ID)
+	if err != nil {
+		log.Printf("❌ Error joining voice to transcribe in guild %s: %v", msg.GuildID, err)
+		gs.mu.Lock()
+		gs.transcript = nil
+		gs.mu.Unlock()
+		cancel()
+		b.reply(msg, "⚠ Join a voice channel first so I know where to listen.")
+		return
+	}
+
+	log.Printf("🎙 Listening to voice in guild %s", msg.GuildID)
+	go b.transcribe(ctx, gs, sub, t)
+
+	wake := b.wakePhraseFor(msg.GuildID)
+	if captions {
+		b.reply(msg, fmt.Sprintf("🎙 Transcribing <#%s>. Everything said there is captioned here until `!transcribe stop`. Say \"%s\" and a command, e.g. \"%s skip\", to control the music.", gs.VoiceChannel(), wake, wake))
+		return
+	}
+	b.reply(msg, fmt.Sprintf("🎙 Listening for voice commands in <#%s>. Say \"%s\" and a command, e.g. \"%s skip\".", gs.VoiceChannel(), wake, wake))
+}
+
+// stopTranscription ends a guild's transcription once its last captions are
+// posted. It reports false when nothing was being transcribed.
+func (b *BotController) stopTranscription(gs *GuildSession) bool {
+	gs.mu.Lock()
+	t := gs.transcript
+	gs.transcript = nil
+	gs.mu.Unlock()
+	if t == nil {
+		return false
+	}
+	t.cancel()
+	<-t.done
+	log.Printf("🛑 Stopped transcribing voice in guild %s", gs.GuildID)
+	return true
+}
+
+// transcribe feeds each speaker's audio to their own recognition stream until
+// ctx is cancelled or the bot leaves voice.
+func (b *BotController) transcribe(ctx context.Context, gs *GuildSession, sub *receive.Subscription, t *transcription) {
+	speakers := make(map[uint32]*speaker)
+	var captions sync.WaitGroup
+	defer func() {
+		b.unsubscribeVoice(gs, sub)
+		for _, sp := range speakers {
+			if sp.audio != nil {
+				close(sp.audio)
+			}
+		}
+		captions.Wait()
+		close(t.done)
+	}()
+
+	ticker := time.NewTicker(speakerIdle / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			for _, sp := range speakers {
+				if sp.audio != nil && time.Since(sp.heard) > speakerIdle {
+					close(sp.audio)
+					sp.audio = nil
+				}
+			}
+
+		case frame, ok := <-sub.Frames():
+			if !ok {
+				return
+			}
+			sp, ok := speakers[frame.SSRC]
+			if !ok {
+				sp = &speaker{}
+				speakers[frame.SSRC] = sp
+			}
+			sp.heard = time.Now()
+			if sp.audio == nil {
+				sp.audio = make(chan []int16, speakerBuffer)
+				captions.Add(1)
+				go func(userID string, audio <-chan []int16) {
+					defer captions.Done()
+					b.caption(t, userID, audio)
+				}(frame.UserID, sp.audio)
+			}
+			select {
+			case sp.audio <- speech.Downsample(frame.PCM):
+			default:
+			}
+		}
+	}
+}
+
+// caption streams one stretch of someone's speech to the recognizer and posts
+// what it makes of it.
+func (b *BotController) caption(t *transcription, userID string, audio <-chan []int16) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := b.Speech.Stream(ctx)
+	if err != nil {
+		log.Printf("❌ Error starting speech recognition: %v", err)
+		for range audio {
+		}
+		return
+	}
+
+	results := stream.Results()
+	var flush <-chan time.Time
+	for results != nil {
+		select {
+		case pcm, ok := <-audio:
+			if ok {
+				err = stream.Write(pcm)
+			}
+			if !ok || err != nil {
+				if err != nil {
+					log.Printf("Error sending speech to the recognizer: %v", err)
+				}
+				stream.Close()
+				audio = nil
+				flush = time.After(captionGrace)
+			}
+		case tr, ok := <-results:
+			if !ok {
+				results = nil
+				continue
+			}
+			text := strings.TrimSpace(tr.Text)
+			if !tr.Final || text == "" {
+				continue
+			}
+			if command, ok := speech.WakeCommand(text, b.wakePhraseFor(t.guildID)); ok {
+				go b.voiceCommand(t, userID, command)
+			} else if t.captions {
+				b.postCaption(t, userID, text)
+			}
+		case <-flush:
+			return
+		}
+	}
+	if audio != nil {
+		stream.Close()
+	}
+}
+
+// postCaption posts a line of recognized speech under the speaker's name.
+func (b *BotController) postCaption(t *transcription, userID, text string) {
+	_, err := b.Session.ChannelMessageSendComplex(t.channelID, &discordgo.MessageSend{
+		Content:         fmt.Sprintf("🗣 **%s**: %s", b.memberName(t.guildID, userID), text),
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	if err != nil {
+		log.Printf("Error posting caption: %v", err)
+	}
+}
+
+// member looks a guild member up in the state cache, falling back to the API.
+func (b *BotController) member(guildID, userID string) (*discordgo.Member, error) {
+	member, err := b.Session.State.Member(guildID, userID)
+	if err != nil {
+		member, err = b.Session.GuildMember(guildID, userID)
+	}
+	return member, err
+}
+
+// memberName returns the name a member goes by in a guild.
+func (b *BotController) memberName(guildID, userID string) string {
+	if userID == "" {
+		return "Someone"
+	}
+	member, err := b.member(guildID, userID)
+	if err != nil || member.User == nil {
+		return "Someone"
+	}
+	if name := member.DisplayName(); name != "" {
+		return name
+	}
+	return member.User.Username
+}
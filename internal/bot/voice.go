@@ -1,205 +1,134 @@
 package bot
 
 import (
-	"bytes"
-	"context"
-	"encoding/binary"
-	"io"
+	"cmp"
+	"fmt"
 	"log"
+	"slices"
+	"strings"
 	"time"
 
-	speech "cloud.google.com/go/speech/apiv1"
-	"cloud.google.com/go/speech/apiv1/speechpb"
+	"github.com/AjStraight619/discord-bot/internal/args"
+
 	"github.com/bwmarrin/dgvoice"
 	"github.com/bwmarrin/discordgo"
 )
 
-// TODO: Maybe implement struct here to control channels more often
-
-type PersonVoice struct {
-	userID   string
-	username string
-}
-
 type VoiceCommandHandler struct {
 	Bot *BotController
 }
 
+// listenQuiet is how long !listen waits for anyone to speak before stopping.
+const listenQuiet = 30 * time.Second
+
+// ListenCommand listens to the voice channel until it goes quiet and reports
+// who spoke and for how long.
 type ListenCommand struct{}
 
-func (lc ListenCommand) Execute(b *BotController, msg *discordgo.MessageCreate, options []string) {
-	b.ListenVoice(msg)
+func (lc ListenCommand) Execute(b *BotController, msg *discordgo.MessageCreate, opts args.Values) {
+	b.ListenVoice(msg, opts.String("user"))
 }
 
 func (lc ListenCommand) Help() string {
-	return "!listen - Listen to voice channel."
+	return "Listen to the voice channel and report how long each person spoke."
 }
 
-func (v *PersonVoice) NewVoice(userID, username string) *PersonVoice {
-	return &PersonVoice{
-		userID:   userID,
-		username: username,
-		// recvChan: make(chan *discordgo.Packet, 2),
-		// sendChan: make(chan *discordgo.Packet, 2),
+func (lc ListenCommand) Args() []args.Spec {
+	return []args.Spec{
+		{Name: "user", Description: "Only listen to this person", Kind: args.User},
 	}
-
 }
 
-func (b *BotController) Echo() {
+func (lc ListenCommand) Category() string { return "Voice" }
 
-	recv := make(chan *discordgo.Packet, 2)
-	go dgvoice.ReceivePCM(b.VoiceConn, recv)
-
-	send := make(chan []int16, 2)
-	go dgvoice.SendPCM(b.VoiceConn, send)
-
-	b.VoiceConn.Speaking(true)
-	defer b.VoiceConn.Speaking(false)
+func (lc ListenCommand) Examples() []string {
+	return []string{"!listen", "!listen @someone"}
+}
 
-	for {
+// Echo plays back what everyone in the voice channel says until the bot
+// leaves it.
+func (b *BotController) Echo(guildID string) {
+	gs := b.Sessions.Get(guildID)
+	vc := gs.Voice()
+	if vc == nil {
+		return
+	}
+	sub, err := b.subscribeVoice(gs, "", "")
+	if err != nil {
+		log.Printf("Error listening to voice in guild %s: %v", guildID, err)
+		return
+	}
+	defer b.unsubscribeVoice(gs, sub)
 
-		p, ok := <-recv
-		if !ok {
-			return
-		}
+	send := make(chan []int16, 2)
+	defer close(send)
+	go dgvoice.SendPCM(gs.Voice(), send)
 
-		send <- p.PCM
+	for frame := range sub.Frames() {
+		send <- frame.PCM
 	}
 }
 
-func (b *BotController) ListenVoice(msg *discordgo.MessageCreate) {
-	recv := make(chan *discordgo.Packet, 2)
-	go dgvoice.ReceivePCM(b.VoiceConn, recv)
-
-	err := b.VoiceConn.Speaking(true)
+// ListenVoice listens to one user, or everyone when userID is empty, until
+// nobody has spoken for listenQuiet, then replies with how long each person
+// spoke.
+func (b *BotController) ListenVoice(msg *discordgo.MessageCreate, userID string) {
+	gs := b.Sessions.Get(msg.GuildID)
+	if gs.Voice() == nil {
+		b.reply(msg, "⚠ I'm not in a voice channel. Use `!join` first.")
+		return
+	}
+	sub, err := b.subscribeVoice(gs, userID, msg.Author.ID)
 	if err != nil {
-		log.Printf("Error setting speaking state: %v", err)
+		log.Printf("Error listening to voice in guild %s: %v", msg.GuildID, err)
+		b.reply(msg, "⚠ Couldn't listen to the voice channel.")
 		return
 	}
-	defer func() {
-		_ = b.VoiceConn.Speaking(false)
-	}()
+	defer b.unsubscribeVoice(gs, sub)
 
 	log.Println("🎙️ Now listening for incoming audio...")
+	b.reply(msg, fmt.Sprintf("👂 Listening until nobody has spoken for %s.", formatDuration(listenQuiet)))
 
+	spoke := make(map[string]time.Duration)
+	quiet := time.NewTimer(listenQuiet)
+	defer quiet.Stop()
 	for {
 		select {
-		case p, ok := <-recv:
+		case frame, ok := <-sub.Frames():
 			if !ok {
 				log.Println("Voice receive channel closed.")
+				b.reply(msg, formatVoiceActivity(b, msg.GuildID, spoke))
 				return
 			}
-
-			log.Printf("Received %d PCM samples", len(p.PCM))
-			if len(p.PCM) > 0 {
-				log.Printf("First sample: %d", p.PCM[0])
+			spoke[frame.UserID] += frameDuration
+			if !quiet.Stop() {
+				<-quiet.C
 			}
-		case <-time.After(30 * time.Second):
-			log.Println("No audio received for 30 seconds, stopping listening.")
+			quiet.Reset(listenQuiet)
+		case <-quiet.C:
+			log.Printf("No audio received for %s, stopping listening.", listenQuiet)
+			b.reply(msg, formatVoiceActivity(b, msg.GuildID, spoke))
 			return
 		}
 	}
 }
 
-func (b *BotController) VoiceToTextStream() {
-	ctx := context.Background()
-	client, err := speech.NewClient(ctx)
-	if err != nil {
-		log.Fatalf("Failed to create speech client: %v", err)
-	}
-	defer client.Close()
-
-	stream, err := client.StreamingRecognize(ctx)
-	if err != nil {
-		log.Fatalf("Failed to open streaming recognize: %v", err)
-	}
-
-	// Send the initial configuration.
-	if err := stream.Send(&speechpb.StreamingRecognizeRequest{
-		StreamingRequest: &speechpb.StreamingRecognizeRequest_StreamingConfig{
-			StreamingConfig: &speechpb.StreamingRecognitionConfig{
-				Config: &speechpb.RecognitionConfig{
-					Encoding:        speechpb.RecognitionConfig_LINEAR16,
-					SampleRateHertz: 16000, // Adjust to match your PCM sample rate.
-					LanguageCode:    "en-US",
-				},
-				InterimResults: true, // Set to false if you only want final results.
-			},
-		},
-	}); err != nil {
-		log.Fatalf("Could not send config: %v", err)
+// formatVoiceActivity lists how long each person spoke, most talkative first.
+func formatVoiceActivity(b *BotController, guildID string, spoke map[string]time.Duration) string {
+	if len(spoke) == 0 {
+		return "🔇 Nobody said anything."
 	}
-
-	// Create a channel to receive the recognized text.
-	textChan := make(chan string)
-
-	// Process the responses from the API in a separate goroutine.
-	go func() {
-		for {
-			resp, err := stream.Recv()
-			if err == io.EOF {
-				break
-			}
-			if err != nil {
-				log.Printf("Error receiving recognition: %v", err)
-				break
-			}
-			// Loop through the results and send the transcript to textChan.
-			for _, result := range resp.Results {
-				for _, alt := range result.Alternatives {
-					textChan <- alt.Transcript
-				}
-			}
-		}
-		close(textChan)
-	}()
-
-	// Now, capture audio from Discord and stream it to the API.
-	recv := make(chan *discordgo.Packet, 2)
-	go dgvoice.ReceivePCM(b.VoiceConn, recv)
-
-	b.VoiceConn.Speaking(true)
-	defer b.VoiceConn.Speaking(false)
-
-	// In a loop, convert and send audio chunks.
-	go func() {
-		for packet := range recv {
-			// Convert the PCM samples (int16 slice) to bytes.
-			audioBytes := ConvertPCMToBytes(packet.PCM)
-			// Send the audio data chunk.
-			err := stream.Send(&speechpb.StreamingRecognizeRequest{
-				StreamingRequest: &speechpb.StreamingRecognizeRequest_AudioContent{
-					AudioContent: audioBytes,
-				},
-			})
-			if err != nil {
-				log.Printf("Error sending audio chunk: %v", err)
-				return
-			}
-		}
-		// After channel is closed, close the stream for the API.
-		err := stream.CloseSend()
-		if err != nil {
-			log.Printf("Error closing stream: %v", err)
-		}
-	}()
-
-	// Consume recognized text and do something with it (e.g., send to Discord channel).
-	for transcript := range textChan {
-		log.Printf("Recognized: %s", transcript)
-		// For example, you could send this transcript as a message:
-		// b.Session.ChannelMessageSend("YOUR_CHANNEL_ID", transcript)
+	users := make([]string, 0, len(spoke))
+	for userID := range spoke {
+		users = append(users, userID)
 	}
-}
+	slices.SortFunc(users, func(a, b string) int { return cmp.Compare(spoke[b], spoke[a]) })
 
-func ConvertPCMToBytes(samples []int16) []byte {
-	buf := new(bytes.Buffer)
-	// Write each int16 sample as little-endian.
-	for _, sample := range samples {
-		// Check error if needed, here we assume no error.
-		_ = binary.Write(buf, binary.LittleEndian, sample)
+	lines := []string{"📊 **Voice activity**"}
+	for _, userID := range users {
+		lines = append(lines, fmt.Sprintf("• **%s**: %s", b.memberName(guildID, userID), formatDuration(spoke[userID])))
 	}
-	return buf.Bytes()
+	return strings.Join(lines, "\n")
 }
 
 // func (b *BotController) ProcessVoiceCommand(transcript, channelID string) {
@@ -0,0 +1,93 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AjStraight619/discord-bot/internal/args"
+	"github.com/bwmarrin/discordgo"
+)
+
+// defaultWakePhrase starts a voice command unless the guild's wake-phrase
+// setting says otherwise.
+const defaultWakePhrase = "hey bot"
+
+// voiceCommands are the commands that can be run by voice. They only control
+// playback, since anyone whose microphone picks up the phrase can use them.
+var voiceCommands = []string{
+	"!play", "!skip", "!pause", "!resume", "!stop", "!np", "!queue",
+	"!shuffle", "!loop", "!volume", "!seek", "!forward", "!rewind",
+}
+
+// spokenNames are words people say instead of a command's name.
+var spokenNames = map[string]string{"next": "skip", "continue": "resume", "unpause": "resume"}
+
+// voiceCommand runs a command someone spoke after the wake phrase as if they
+// had typed it in the channel the transcription posts to.
+func (b *BotController) voiceCommand(t *transcription, userID, command string) {
+	if userID == "" {
+		log.Printf("Ignoring voice command %q from an unknown speaker", command)
+		return
+	}
+	member, err := b.member(t.guildID, userID)
+	if err != nil || member.User == nil {
+		log.Printf("Error getting member %s for voice command: %v", userID, err)
+		return
+	}
+
+	words := strings.Fields(command)
+	if spoken, ok := spokenNames[words[0]]; ok {
+		words[0] = spoken
+	}
+	inv := args.Invocation{Name: commandName(words[0]), Words: words[1:]}
+	name := b.memberName(t.guildID, userID)
+
+	if _, ok := b.CommandRegistry.Get(inv.Name); !ok {
+		b.postVoiceNotice(t, fmt.Sprintf("🎙 **%s**, I heard \"%s\" but that isn't a command.", name, command))
+		return
+	}
+	if !slices.Contains(voiceCommands, inv.Name) {
+		b.postVoiceNotice(t, fmt.Sprintf("⚠ **%s**, `%s` can't be used by voice.", name, inv.Name))
+		return
+	}
+
+	prefix := b.prefixFor(t.guildID)
+	content := strings.TrimSpace(prefix + strings.TrimPrefix(inv.Name, "!") + " " + strings.Join(inv.Words, " "))
+	log.Printf("🎙 Voice command from %s: %s", name, content)
+	b.postVoiceNotice(t, fmt.Sprintf("🎙 **%s** said: `%s`", name, content))
+
+	msg := &discordgo.MessageCreate{
+		Message: &discordgo.Message{
+			ID:        fmt.Sprintf("voice-%s-%d", userID, time.Now().UnixNano()),
+			ChannelID: t.channelID,
+			GuildID:   t.guildID,
+			Author:    member.User,
+			Member:    member,
+			Content:   content,
+		},
+	}
+	b.ResetTimeout(t.guildID)
+	b.runCommand(msg, inv)
+}
+
+// postVoiceNotice posts a message about a voice command without pinging anyone.
+func (b *BotController) postVoiceNotice(t *transcription, content string) {
+	_, err := b.Session.ChannelMessageSendComplex(t.channelID, &discordgo.MessageSend{
+		Content:         content,
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	if err != nil {
+		log.Printf("Error posting voice command notice: %v", err)
+	}
+}
+
+// wakePhraseFor returns the phrase that starts a voice command in a guild.
+func (b *BotController) wakePhraseFor(guildID string) string {
+	if phrase := b.guildSettings(guildID).WakePhrase; phrase != "" {
+		return phrase
+	}
+	return defaultWakePhrase
+}
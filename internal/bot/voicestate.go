@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"log"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// defaultAloneTimeout is how long the bot stays in a voice channel nobody else
+// is in, unless the guild's alone-timeout setting says otherwise.
+const defaultAloneTimeout = time.Minute
+
+// VoiceStateUpdateHandler watches members joining, leaving and moving between
+// voice channels, and starts the alone timer when the last person leaves the
+// bot's channel. Updates about the bot itself track it being moved or
+// disconnected.
+func (b *BotController) VoiceStateUpdateHandler(s *discordgo.Session, vsu *discordgo.VoiceStateUpdate) {
+	gs, ok := b.Sessions.Lookup(vsu.GuildID)
+	if !ok {
+		return
+	}
+	if vsu.UserID == s.State.User.ID {
+		b.botVoiceStateUpdate(gs, vsu)
+		return
+	}
+	vc := gs.Voice()
+	if vc == nil {
+		return
+	}
+
+	// Only changes to the bot's channel matter: someone joining or leaving it.
+	joined := vsu.ChannelID == vc.ChannelID
+	left := vsu.BeforeUpdate != nil && vsu.BeforeUpdate.ChannelID == vc.ChannelID
+	if !joined && !left {
+		return
+	}
+	if joined && (vsu.BeforeUpdate == nil || vsu.BeforeUpdate.ChannelID != vc.ChannelID) {
+		b.recordingJoined(gs, vsu.UserID)
+	}
+	b.checkAlone(gs, vc.ChannelID)
+}
+
+// checkAlone starts or stops the guild's alone timer depending on whether
+// anyone other than bots is in the bot's voice channel.
+func (b *BotController) checkAlone(gs *GuildSession, channelID string) {
+	alone := b.humansIn(gs.GuildID, channelID) == 0
+	timeout := b.aloneTimeoutFor(gs.GuildID)
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	if !alone {
+		if gs.aloneTimer != nil {
+			log.Printf("👥 Someone rejoined the voice channel in guild %s", gs.GuildID)
+			gs.aloneTimer.Stop()
+			gs.aloneTimer = nil
+		}
+		return
+	}
+	if gs.aloneTimer != nil {
+		return
+	}
+
+	log.Printf("👤 Alone in the voice channel in guild %s, leaving in %v", gs.GuildID, timeout)
+	var timer *time.Timer
+	timer = time.AfterFunc(timeout, func() {
+		gs.mu.Lock()
+		current := gs.aloneTimer == timer
+		gs.mu.Unlock()
+		if current && b.humansIn(gs.GuildID, channelID) == 0 {
+			b.LeaveVoiceChannel(gs.GuildID, leftAlone)
+		}
+	})
+	gs.aloneTimer = timer
+}
+
+// humansIn counts the members in a voice channel that aren't bots.
+func (b *BotController) humansIn(guildID, channelID string) int {
+	return len(b.humanIDsIn(guildID, channelID))
+}
+
+// humanIDsIn returns the IDs of the members in a voice channel that aren't bots.
+func (b *BotController) humanIDsIn(guildID, channelID string) []string {
+	guild, err := b.Session.State.Guild(guildID)
+	if err != nil {
+		log.Printf("Error getting guild %s from state: %v", guildID, err)
+		return nil
+	}
+
+	var humans []string
+	for _, vs := range guild.VoiceStates {
+		if vs.ChannelID != channelID {
+			continue
+		}
+		member := vs.Member
+		if member == nil {
+			member, _ = b.Session.State.Member(guildID, vs.UserID)
+		}
+		if member != nil && member.User != nil && member.User.Bot {
+			continue
+		}
+		humans = append(humans, vs.UserID)
+	}
+	return humans
+}
+
+// aloneTimeoutFor returns how long the bot waits in an otherwise empty voice
+// channel before leaving.
+func (b *BotController) aloneTimeoutFor(guildID string) time.Duration {
+	if seconds := b.guildSettings(guildID).AloneTimeoutSeconds; seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return defaultAloneTimeout
+}
@@ -3,6 +3,9 @@ package config
 import (
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -12,6 +15,20 @@ type Config struct {
 	OpenAIKey  string
 	NewsKey    string
 	SportsKey  string
+	DBPath     string // Where bot state is persisted, defaults to data/bot.db.
+
+	AudioCacheDir string // Where songs are cached, defaults to audio.
+	AudioCacheMB  int64  // Size limit of the audio cache, defaults to 1024.
+
+	MusicLibraryDir string // Local music that !play local: can play, disabled when empty.
+	MaxAudioFileMB  int64  // Largest upload or library file that can be played, defaults to 50.
+
+	SpeechEngine   string // Speech-to-text for !transcribe: google, command or fake. Disabled when empty.
+	SpeechCommand  string // Offline engine run by the command engine, with its arguments.
+	SpeechLanguage string // Language spoken in voice channels, defaults to en-US.
+
+	RecordingsDir string // Where !record saves recordings, defaults to recordings.
+	RecordingsURL string // Public URL RecordingsDir is served at, for recordings too big to upload.
 }
 
 var AppConfig *Config
@@ -27,6 +44,57 @@ func LoadConfig() *Config {
 		OpenAIKey:  os.Getenv("OPENAI_KEY"),
 		NewsKey:    os.Getenv("NEWS_KEY"),
 		SportsKey:  os.Getenv("SPORTS_RADAR_KEY"),
+		DBPath:     os.Getenv("BOT_DB_PATH"),
+
+		AudioCacheDir: os.Getenv("AUDIO_CACHE_DIR"),
+		AudioCacheMB:  1024,
+
+		MusicLibraryDir: os.Getenv("MUSIC_LIBRARY_DIR"),
+		MaxAudioFileMB:  50,
+
+		SpeechEngine:   os.Getenv("SPEECH_ENGINE"),
+		SpeechCommand:  os.Getenv("SPEECH_COMMAND"),
+		SpeechLanguage: os.Getenv("SPEECH_LANGUAGE"),
+
+		RecordingsDir: os.Getenv("RECORDINGS_DIR"),
+		RecordingsURL: os.Getenv("RECORDINGS_URL"),
+	}
+
+	if cfg.DBPath == "" {
+		cfg.DBPath = filepath.Join("data", "bot.db")
+	}
+	if cfg.AudioCacheDir == "" {
+		cfg.AudioCacheDir = "audio"
+	}
+	if cfg.RecordingsDir == "" {
+		cfg.RecordingsDir = "recordings"
+	}
+	if mb := os.Getenv("AUDIO_CACHE_MB"); mb != "" {
+		n, err := strconv.ParseInt(mb, 10, 64)
+		if err != nil || n <= 0 {
+			log.Fatalf("Invalid AUDIO_CACHE_MB %q", mb)
+		}
+		cfg.AudioCacheMB = n
+	}
+	if mb := os.Getenv("MAX_AUDIO_FILE_MB"); mb != "" {
+		n, err := strconv.ParseInt(mb, 10, 64)
+		if err != nil || n <= 0 {
+			log.Fatalf("Invalid MAX_AUDIO_FILE_MB %q", mb)
+		}
+		cfg.MaxAudioFileMB = n
+	}
+
+	if cfg.SpeechLanguage == "" {
+		cfg.SpeechLanguage = "en-US"
+	}
+	switch cfg.SpeechEngine {
+	case "", "google", "fake":
+	case "command":
+		if len(strings.Fields(cfg.SpeechCommand)) == 0 {
+			log.Fatal("SPEECH_ENGINE=command needs SPEECH_COMMAND")
+		}
+	default:
+		log.Fatalf("Invalid SPEECH_ENGINE %q, expected google, command or fake", cfg.SpeechEngine)
 	}
 
 	if cfg.OpenAIKey == "" || cfg.NewsKey == "" || cfg.SportsKey == "" || cfg.DiscordKey == "" {
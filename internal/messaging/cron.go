@@ -9,6 +9,7 @@ import (
 	"github.com/AjStraight619/discord-bot/internal/members"
 	"github.com/AjStraight619/discord-bot/internal/models"
 	sportsutils "github.com/AjStraight619/discord-bot/internal/sports_utils"
+	"github.com/AjStraight619/discord-bot/internal/storage"
 	"github.com/AjStraight619/discord-bot/internal/utils"
 	"github.com/bwmarrin/discordgo"
 	"github.com/robfig/cron/v3"
@@ -19,103 +20,119 @@ type CronMessage struct {
 	Session  *discordgo.Session
 	duration int
 	cron     *cron.Cron
-	cronMsg  string
-	schedule string
+	jobs     *storage.Repository[storage.ScheduledDM]
 }
 
-// InitCron initializes a new CronMessages instance.
-func InitCron(dg *discordgo.Session, dur int) *CronMessage {
+// InitCron initializes a new CronMessages instance backed by the saved jobs in store.
+func InitCron(dg *discordgo.Session, dur int, store storage.Store) *CronMessage {
 	return &CronMessage{
 		Session:  dg,
 		duration: dur,
 		cron:     cron.New(),
-		cronMsg:  "",
-		schedule: "",
+		jobs:     storage.NewRepository[storage.ScheduledDM](store, storage.BucketJobs),
 	}
 }
 
-// StartCydCron loads team stats, formats the message, and schedules a DM cron job.
-func (cm *CronMessage) StartCydCron() {
-	// Load team data.
-
-	// TODO: Move LoadTeams to sports utils package. Bot should not know about loading teams.
-	teams := sportsutils.LoadTeams()
-	if teams == nil {
-		log.Printf("Failed to load teams")
+// StartJobs schedules every saved DM job and starts the cron scheduler.
+func (cm *CronMessage) StartJobs() {
+	jobs, err := cm.jobs.All()
+	if err != nil {
+		log.Printf("Error loading scheduled jobs: %v", err)
 		return
 	}
 
-	lakers, err := teams.FindTeam("Lakers")
-	if err != nil {
-		log.Printf("Failed to find team: %v", err)
-		return
+	for id, job := range jobs {
+		id := id
+		_, err := cm.cron.AddFunc(job.Schedule, func() {
+			cm.runJob(id)
+		})
+		if err != nil {
+			log.Printf("Error scheduling cron job %s: %v", id, err)
+			continue
+		}
+		log.Printf("Cron job %s scheduled for %s with schedule: %s", id, job.Username, job.Schedule)
 	}
 
-	teamStats, err := apiclients.GetTeamStatistics(lakers.ID, "2024", "REG")
+	// Start the cron scheduler.
+	cm.cron.Start()
+}
+
+// runJob reloads the job so edits made since startup are picked up, resolves the
+// target member and sends them the player's stats.
+func (cm *CronMessage) runJob(id string) {
+	job, err := cm.jobs.Get(id)
 	if err != nil {
-		log.Printf("Failed to get team stats: %v", err)
+		log.Printf("Skipping cron job %s: %v", id, err)
 		return
 	}
 
-	log.Printf("Team Stats: %+v", teamStats)
-	log.Printf("Team: %s", teamStats.Name)
-	log.Printf("Market: %s", teamStats.Market)
-	log.Printf("Season: %d %s", teamStats.Season.Year, teamStats.Season.Type)
-	log.Printf("Games Played: %d", teamStats.OwnRecord.Total.GamesPlayed)
-
-	// Look for LeBron James in the players list and format his stats.
-	for _, player := range teamStats.Players {
-		if strings.EqualFold("LeBron James", player.FullName) {
-			log.Printf("Found player: %s", player.FullName)
-			// Optionally log some averages:
-			log.Printf("Minutes: %.2f | Points: %.2f", player.Averages.Minutes, player.Averages.Points)
-			cm.cronMsg = FormatPlayerStatsMessage(player)
-			break
+	if job.UserID == "" {
+		job.UserID, err = cm.findMember(job.GuildName, job.Username)
+		if err != nil {
+			log.Printf("Error resolving target for cron job %s: %v", id, err)
+			return
+		}
+		// Remember the target so later runs don't have to fetch every member.
+		if err := cm.jobs.Put(id, job); err != nil {
+			log.Printf("Error saving cron job %s: %v", id, err)
 		}
 	}
 
-	// Find the guild by name.
-	guild := utils.FindGuildByName(cm.Session, "King's Landing")
-	if guild == nil {
-		log.Printf("Error finding guild...")
+	message, err := PlayerStatsMessage(job.Team, job.Player, job.Season)
+	if err != nil {
+		log.Printf("Error building message for cron job %s: %v", id, err)
 		return
 	}
 
-	// Fetch all guild members.
+	SendDM(cm.Session, job.UserID, message)
+}
+
+// findMember looks up a member's user ID by username in the named guild.
+func (cm *CronMessage) findMember(guildName, username string) (string, error) {
+	guild := utils.FindGuildByName(cm.Session, guildName)
+	if guild == nil {
+		return "", fmt.Errorf("guild %q not found", guildName)
+	}
+
 	allMembers, err := members.FetchAllGuildMembers(cm.Session, guild.ID)
 	if err != nil {
-		log.Printf("Error fetching guild members: %v", err)
-		return
+		return "", fmt.Errorf("fetching guild members: %w", err)
 	}
 
-	// Find the target member with username "cydstynine".
-	var targetUserID string
 	for _, member := range allMembers {
-		if strings.EqualFold(member.User.Username, "cydstynine") {
-			targetUserID = member.User.ID
-			break
+		if strings.EqualFold(member.User.Username, username) {
+			return member.User.ID, nil
 		}
 	}
-	if targetUserID == "" {
-		log.Printf("Member with username 'cydstynine' not found")
-		return
+	return "", fmt.Errorf("member with username %q not found", username)
+}
+
+// PlayerStatsMessage loads a team's season stats and formats the averages of
+// one of its players.
+func PlayerStatsMessage(teamName, playerName, season string) (string, error) {
+	// TODO: Move LoadTeams to sports utils package. Bot should not know about loading teams.
+	teams := sportsutils.LoadTeams()
+	if teams == nil {
+		return "", fmt.Errorf("failed to load teams")
 	}
 
-	schedule := "@daily"
+	team, err := teams.FindTeam(teamName)
+	if err != nil {
+		return "", err
+	}
 
-	// Add the cron job to send a DM with the formatted message.
-	_, err = cm.cron.AddFunc(schedule, func() {
-		// Use your SendDM function (from your bot package or wherever it is defined).
-		SendDM(cm.Session, targetUserID, cm.cronMsg)
-	})
+	teamStats, err := apiclients.GetTeamStatistics(team.ID, season, "REG")
 	if err != nil {
-		log.Printf("Error scheduling cron job: %v", err)
-		return
+		return "", fmt.Errorf("failed to get team stats: %w", err)
 	}
 
-	// Start the cron scheduler.
-	cm.cron.Start()
-	log.Printf("Cron job started for user %s with schedule: %s", targetUserID, schedule)
+	for _, player := range teamStats.Players {
+		if strings.EqualFold(playerName, player.FullName) {
+			log.Printf("Minutes: %.2f | Points: %.2f", player.Averages.Minutes, player.Averages.Points)
+			return FormatPlayerStatsMessage(player), nil
+		}
+	}
+	return "", fmt.Errorf("player %q not found on %s", playerName, teamStats.Name)
 }
 
 // FormatPlayerStatsMessage formats the player's averages into a message string.
@@ -0,0 +1,68 @@
+package receive
+
+import "github.com/bwmarrin/discordgo"
+
+// jitterBuffer puts one speaker's packets back in order. UDP can deliver them
+// late, twice or not at all, so a packet that arrives after a gap is held
+// until the gap is filled or more than depth packets are waiting, at which
+// point the missing packet is given up on.
+type jitterBuffer struct {
+	depth   int
+	next    uint16 // Sequence number of the next packet to release.
+	started bool
+	pending map[uint16]*discordgo.Packet
+}
+
+func newJitterBuffer(depth int) *jitterBuffer {
+	return &jitterBuffer{depth: depth, pending: make(map[uint16]*discordgo.Packet)}
+}
+
+// push adds a packet and returns the packets that are ready, in order. A nil
+// entry stands for a packet that was lost.
+func (j *jitterBuffer) push(p *discordgo.Packet) []*discordgo.Packet {
+	if !j.started {
+		j.next = p.Sequence
+		j.started = true
+	}
+	if before(p.Sequence, j.next) {
+		return nil // Too late, or a duplicate of a released packet.
+	}
+	j.pending[p.Sequence] = p
+
+	var ready []*discordgo.Packet
+	for len(j.pending) > 0 {
+		if p, ok := j.pending[j.next]; ok {
+			ready = append(ready, p)
+			delete(j.pending, j.next)
+		} else if len(j.pending) > j.depth {
+			ready = append(ready, nil)
+		} else {
+			break
+		}
+		j.next++
+	}
+	return ready
+}
+
+// flush releases every held packet, gaps included, and starts over with the
+// next packet that arrives. It's called when the speaker goes quiet, since
+// nothing will fill the gaps then.
+func (j *jitterBuffer) flush() []*discordgo.Packet {
+	var ready []*discordgo.Packet
+	for len(j.pending) > 0 {
+		p, ok := j.pending[j.next]
+		if ok {
+			delete(j.pending, j.next)
+		}
+		ready = append(ready, p)
+		j.next++
+	}
+	j.started = false
+	return ready
+}
+
+// before reports whether sequence number a comes before b, allowing for the
+// numbers wrapping around.
+func before(a, b uint16) bool {
+	return int16(a-b) < 0
+}
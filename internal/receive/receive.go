@@ -0,0 +1,201 @@
+// Package receive splits the audio Discord sends a voice connection into one
+// stream per speaker. Packets are matched to users by their SSRC, put back in
+// order and decoded to PCM, which any number of subscribers can listen to.
+package receive
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"layeh.com/gopus"
+)
+
+// Discord voice is 48 kHz stereo Opus in 20ms frames.
+const (
+	SampleRate = 48000
+	Channels   = 2
+	FrameSize  = 960 // Samples per channel in a frame.
+)
+
+const (
+	// jitterDepth is how many packets may wait behind a missing one before
+	// it's treated as lost, 60ms of audio.
+	jitterDepth = 3
+	// subscriberBuffer is how many frames a subscriber may fall behind before
+	// frames are dropped for it.
+	subscriberBuffer = 100
+)
+
+// Frame is 20ms of one speaker's audio.
+type Frame struct {
+	UserID    string    // Empty until Discord says who the SSRC belongs to.
+	SSRC      uint32    // Identifies the speaker's stream on this connection.
+	Timestamp uint32    // RTP timestamp, counting samples per channel.
+	Received  time.Time // When the packet arrived.
+	PCM       []int16   // Interleaved stereo at SampleRate. Shared, don't modify.
+}
+
+// Receiver decodes the packets of a voice connection for its subscribers.
+type Receiver struct {
+	mu       sync.Mutex
+	users    map[uint32]string // SSRC -> user ID.
+	speakers map[uint32]*speaker
+	subs     map[*Subscription]struct{}
+	closed   bool
+}
+
+// speaker is the decoding state of one SSRC.
+type speaker struct {
+	decoder *gopus.Decoder
+	jitter  *jitterBuffer
+	heard   time.Time
+}
+
+func New() *Receiver {
+	return &Receiver{
+		users:    make(map[uint32]string),
+		speakers: make(map[uint32]*speaker),
+		subs:     make(map[*Subscription]struct{}),
+	}
+}
+
+// Speaking records which user an SSRC belongs to. Register it with the
+// connection's speaking updates, since packets only carry the SSRC.
+func (r *Receiver) Speaking(vc *discordgo.VoiceConnection, vs *discordgo.VoiceSpeakingUpdate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[uint32(vs.SSRC)] = vs.UserID
+}
+
+// Push decodes a packet received on the voice connection and hands the
+// frames that are ready to the subscribers.
+func (r *Receiver) Push(p *discordgo.Packet) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+
+	sp, ok := r.speakers[p.SSRC]
+	if !ok {
+		decoder, err := gopus.NewDecoder(SampleRate, Channels)
+		if err != nil {
+			log.Printf("Error creating opus decoder: %v", err)
+			return
+		}
+		sp = &speaker{decoder: decoder, jitter: newJitterBuffer(jitterDepth)}
+		r.speakers[p.SSRC] = sp
+	}
+	sp.heard = time.Now()
+	r.play(p.SSRC, sp, sp.jitter.push(p))
+}
+
+// Flush releases the packets held for speakers who have been quiet for idle,
+// as nothing is coming to fill the gaps in front of them.
+func (r *Receiver) Flush(idle time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for ssrc, sp := range r.speakers {
+		if sp.jitter.started && time.Since(sp.heard) > idle {
+			r.play(ssrc, sp, sp.jitter.flush())
+		}
+	}
+}
+
+// play decodes packets in order and publishes them. Lost packets are
+// recovered from the forward error correction data in the packet after them
+// when there is one, and are silence otherwise. Callers hold r.mu.
+func (r *Receiver) play(ssrc uint32, sp *speaker, packets []*discordgo.Packet) {
+	for i, p := range packets {
+		var pcm []int16
+		var err error
+		switch {
+		case p != nil:
+			pcm, err = sp.decoder.Decode(p.Opus, FrameSize, false)
+		case i+1 < len(packets) && packets[i+1] != nil:
+			pcm, err = sp.decoder.Decode(packets[i+1].Opus, FrameSize, true)
+		default:
+			pcm = make([]int16, FrameSize*Channels)
+		}
+		if err != nil {
+			continue
+		}
+
+		frame := Frame{UserID: r.users[ssrc], SSRC: ssrc, Received: sp.heard, PCM: pcm}
+		if p != nil {
+			frame.Timestamp = p.Timestamp
+		} else if i+1 < len(packets) && packets[i+1] != nil {
+			frame.Timestamp = packets[i+1].Timestamp - FrameSize
+		}
+		r.publish(frame)
+	}
+}
+
+// publish hands a frame to the subscribers that want it. Callers hold r.mu.
+func (r *Receiver) publish(frame Frame) {
+	for sub := range r.subs {
+		if sub.userID != "" && sub.userID != frame.UserID {
+			continue
+		}
+		select {
+		case sub.frames <- frame:
+		default:
+		}
+	}
+}
+
+// Subscribe starts delivering the frames of one user, or of everyone when
+// userID is empty.
+func (r *Receiver) Subscribe(userID string) *Subscription {
+	sub := &Subscription{r: r, userID: userID, frames: make(chan Frame, subscriberBuffer)}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		close(sub.frames)
+		return sub
+	}
+	r.subs[sub] = struct{}{}
+	return sub
+}
+
+// Subscribers returns how many subscriptions are open.
+func (r *Receiver) Subscribers() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.subs)
+}
+
+// Close ends every subscription.
+func (r *Receiver) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	for sub := range r.subs {
+		close(sub.frames)
+		delete(r.subs, sub)
+	}
+}
+
+// Subscription delivers the frames of one user, or of everyone.
+type Subscription struct {
+	r      *Receiver
+	userID string
+	frames chan Frame
+}
+
+// Frames returns the subscribed audio. It's closed once the subscription or
+// the receiver is.
+func (s *Subscription) Frames() <-chan Frame { return s.frames }
+
+// Close stops the subscription.
+func (s *Subscription) Close() {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	if _, ok := s.r.subs[s]; ok {
+		close(s.frames)
+		delete(s.r.subs, s)
+	}
+}
@@ -0,0 +1,112 @@
+package receive
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"layeh.com/gopus"
+)
+
+func packet(seq uint16) *discordgo.Packet {
+	return &discordgo.Packet{SSRC: 1, Sequence: seq, Timestamp: uint32(seq) * FrameSize}
+}
+
+func sequences(packets []*discordgo.Packet) []int {
+	var seqs []int
+	for _, p := range packets {
+		if p == nil {
+			seqs = append(seqs, -1)
+		} else {
+			seqs = append(seqs, int(p.Sequence))
+		}
+	}
+	return seqs
+}
+
+func TestJitterBuffer(t *testing.T) {
+	j := newJitterBuffer(2)
+	var got []int
+	for _, seq := range []uint16{10, 12, 11, 11, 13, 15, 16, 17, 9} {
+		got = append(got, sequences(j.push(packet(seq)))...)
+	}
+	got = append(got, sequences(j.flush())...)
+
+	// 12 waits for 11; the duplicate 11 and the late 9 are dropped; 14 is
+	// given up on once three packets wait behind it.
+	want := []int{10, 11, 12, 13, -1, 15, 16, 17}
+	if len(got) != len(want) {
+		t.Fatalf("released %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("released %v, want %v", got, want)
+		}
+	}
+}
+
+func TestJitterBufferWraps(t *testing.T) {
+	j := newJitterBuffer(2)
+	var got []int
+	for _, seq := range []uint16{65534, 0, 65535, 1} {
+		got = append(got, sequences(j.push(packet(seq)))...)
+	}
+	want := []int{65534, 65535, 0, 1}
+	for i := range want {
+		if i >= len(got) || got[i] != want[i] {
+			t.Fatalf("released %v, want %v", got, want)
+		}
+	}
+}
+
+func TestReceiverSubscriptions(t *testing.T) {
+	encoder, err := gopus.NewEncoder(SampleRate, Channels, gopus.Voip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opus, err := encoder.Encode(make([]int16, FrameSize*Channels), FrameSize, 4000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := New()
+	alice := r.Subscribe("alice")
+	everyone := r.Subscribe("")
+	r.Speaking(nil, &discordgo.VoiceSpeakingUpdate{UserID: "alice", SSRC: 1})
+	r.Speaking(nil, &discordgo.VoiceSpeakingUpdate{UserID: "bob", SSRC: 2})
+
+	for seq := uint16(0); seq < 3; seq++ {
+		r.Push(&discordgo.Packet{SSRC: 1, Sequence: seq, Opus: opus})
+		r.Push(&discordgo.Packet{SSRC: 2, Sequence: seq, Opus: opus})
+	}
+	r.Flush(0)
+
+	if got := len(alice.Frames()); got != 3 {
+		t.Errorf("alice's subscription got %d frames, want 3", got)
+	}
+	if got := len(everyone.Frames()); got != 6 {
+		t.Errorf("everyone's subscription got %d frames, want 6", got)
+	}
+	frame := <-alice.Frames()
+	if frame.UserID != "alice" || len(frame.PCM) != FrameSize*Channels {
+		t.Errorf("got frame from %q with %d samples, want alice with %d", frame.UserID, len(frame.PCM), FrameSize*Channels)
+	}
+
+	alice.Close()
+	alice.Close()
+	r.Close()
+	if _, ok := <-everyone.Frames(); !ok {
+		t.Error("closing the receiver dropped buffered frames")
+	}
+	if r.Subscribers() != 0 {
+		t.Errorf("%d subscribers left after Close", r.Subscribers())
+	}
+	select {
+	case _, ok := <-r.Subscribe("").Frames():
+		if ok {
+			t.Error("subscribing to a closed receiver delivered a frame")
+		}
+	case <-time.After(time.Second):
+		t.Error("subscribing to a closed receiver didn't close the subscription")
+	}
+}
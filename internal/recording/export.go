@@ -0,0 +1,107 @@
+package recording
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// Format is a file format recordings are saved in.
+type Format string
+
+const (
+	WAV Format = "wav" // Uncompressed, about 5.5 MB a minute.
+	Ogg Format = "ogg" // Opus, about 240 KB a minute.
+)
+
+// Export writes the raw track at raw to dst in format.
+func Export(raw, dst string, format Format) (err error) {
+	in, err := os.Open(raw)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	buf := bufio.NewWriterSize(out, 64<<10)
+
+	switch format {
+	case WAV:
+		err = WriteWAV(buf, bufio.NewReader(in), info.Size())
+	case Ogg:
+		err = WriteOgg(buf, in)
+	default:
+		err = fmt.Errorf("unknown recording format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+// Mix adds raw tracks together into a raw track at dst, clipping where the
+// sum is too loud. Tracks that end early are treated as silence.
+func Mix(dst string, tracks ...string) (err error) {
+	var inputs []*bufio.Reader
+	for _, path := range tracks {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		inputs = append(inputs, bufio.NewReader(f))
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	return mix(bufio.NewWriter(out), inputs)
+}
+
+// mix sums the samples of inputs into w until every input ends, then flushes w.
+func mix(w *bufio.Writer, inputs []*bufio.Reader) error {
+	var sample [2]byte
+	for {
+		sum, playing := int32(0), false
+		for _, in := range inputs {
+			if _, err := io.ReadFull(in, sample[:]); err != nil {
+				if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+					return err
+				}
+				continue
+			}
+			sum += int32(int16(binary.LittleEndian.Uint16(sample[:])))
+			playing = true
+		}
+		if !playing {
+			return w.Flush()
+		}
+		sum = max(math.MinInt16, min(math.MaxInt16, sum))
+		binary.LittleEndian.PutUint16(sample[:], uint16(int16(sum)))
+		if _, err := w.Write(sample[:]); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package recording
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/rand"
+
+	"layeh.com/gopus"
+)
+
+const (
+	// oggBitrate suits speech, about 240 KB a minute.
+	oggBitrate = 32000
+	// preSkip is the encoder's lookahead, which players drop from the start.
+	preSkip = 312
+	// pagePackets is how many packets go in a page, a second of audio.
+	pagePackets = 50
+	// maxPacket bounds the size of an encoded frame.
+	maxPacket = 4000
+)
+
+// Ogg page header flags.
+const (
+	pageFirst = 0x02
+	pageLast  = 0x04
+)
+
+// WriteOgg encodes raw mono PCM from pcm as Opus and writes it to w in an Ogg
+// container, as RFC 7845 describes.
+func WriteOgg(w io.Writer, pcm io.Reader) error {
+	encoder, err := gopus.NewEncoder(SampleRate, 1, gopus.Voip)
+	if err != nil {
+		return err
+	}
+	encoder.SetBitrate(oggBitrate)
+
+	ogg := &oggWriter{w: w, serial: rand.Uint32()}
+	head := []byte("OpusHead")
+	head = append(head, 1, 1) // Version, channels.
+	head = binary.LittleEndian.AppendUint16(head, preSkip)
+	head = binary.LittleEndian.AppendUint32(head, SampleRate)
+	head = append(head, 0, 0, 0) // Output gain, channel mapping family.
+	if err := ogg.page(pageFirst, 0, [][]byte{head}); err != nil {
+		return err
+	}
+	vendor := "discord-bot"
+	tags := []byte("OpusTags")
+	tags = binary.LittleEndian.AppendUint32(tags, uint32(len(vendor)))
+	tags = append(tags, vendor...)
+	tags = binary.LittleEndian.AppendUint32(tags, 0) // No comments.
+	if err := ogg.page(0, 0, [][]byte{tags}); err != nil {
+		return err
+	}
+
+	in := bufio.NewReader(pcm)
+	frame := make([]int16, FrameSize)
+	raw := make([]byte, 2*FrameSize)
+	var packets [][]byte
+	var samples int64
+	for {
+		n, err := io.ReadFull(in, raw)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return err
+		}
+		clear(raw[n:])
+		for i := range frame {
+			frame[i] = int16(binary.LittleEndian.Uint16(raw[2*i:]))
+		}
+		packet, err := encoder.Encode(frame, FrameSize, maxPacket)
+		if err != nil {
+			return err
+		}
+		packets = append(packets, packet)
+		samples += int64(n / 2)
+
+		if len(packets) == pagePackets {
+			if err := ogg.page(0, uint64(preSkip+samples), packets); err != nil {
+				return err
+			}
+			packets = packets[:0]
+		}
+	}
+	// The last page's granule position trims the padding off the last frame.
+	return ogg.page(pageLast, uint64(preSkip+samples), packets)
+}
+
+// oggWriter writes the pages of a single logical Ogg stream.
+type oggWriter struct {
+	w        io.Writer
+	serial   uint32
+	sequence uint32
+}
+
+// page writes packets as one page, each of them complete.
+func (o *oggWriter) page(flags byte, granule uint64, packets [][]byte) error {
+	var lacing []byte
+	size := 0
+	for _, p := range packets {
+		for n := len(p); ; n -= 255 {
+			if n < 255 {
+				lacing = append(lacing, byte(n))
+				break
+			}
+			lacing = append(lacing, 255)
+		}
+		size += len(p)
+	}
+	if len(lacing) > 255 {
+		return errors.New("too many packets for an ogg page")
+	}
+
+	page := make([]byte, 0, 27+len(lacing)+size)
+	page = append(page, "OggS"...)
+	page = append(page, 0, flags)
+	page = binary.LittleEndian.AppendUint64(page, granule)
+	page = binary.LittleEndian.AppendUint32(page, o.serial)
+	page = binary.LittleEndian.AppendUint32(page, o.sequence)
+	page = binary.LittleEndian.AppendUint32(page, 0) // Checksum, filled in below.
+	page = append(page, byte(len(lacing)))
+	page = append(page, lacing...)
+	for _, p := range packets {
+		page = append(page, p...)
+	}
+	binary.LittleEndian.PutUint32(page[22:], oggChecksum(page))
+
+	o.sequence++
+	_, err := o.w.Write(page)
+	return err
+}
+
+// oggCRC is the table for Ogg's CRC-32: polynomial 0x04c11db7, unreflected,
+// starting from zero.
+var oggCRC = func() [256]uint32 {
+	var table [256]uint32
+	for i := range table {
+		crc := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if crc&0x80000000 != 0 {
+				crc = crc<<1 ^ 0x04c11db7
+			} else {
+				crc <<= 1
+			}
+		}
+		table[i] = crc
+	}
+	return table
+}()
+
+func oggChecksum(page []byte) uint32 {
+	var crc uint32
+	for _, b := range page {
+		crc = crc<<8 ^ oggCRC[byte(crc>>24)^b]
+	}
+	return crc
+}
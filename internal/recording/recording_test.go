@@ -0,0 +1,206 @@
+package recording
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// stereo returns a frame of interleaved stereo audio at a constant level.
+func stereo(level int16) []int16 {
+	pcm := make([]int16, 2*FrameSize)
+	for i := range pcm {
+		pcm[i] = level
+	}
+	return pcm
+}
+
+// readSamples loads a raw track.
+func readSamples(t *testing.T, path string) []int16 {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples := make([]int16, len(data)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(data[2*i:]))
+	}
+	return samples
+}
+
+func TestTrackPlacesFrames(t *testing.T) {
+	track, err := NewTrack(filepath.Join(t.TempDir(), "alice.pcm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first frame arrives 20ms in, the next is a frame later by its RTP
+	// clock, then after a second of silence a reconnect starts a new SSRC.
+	steps := []struct {
+		ssrc, timestamp uint32
+		at              time.Duration
+	}{
+		{1, 5000, 20 * time.Millisecond},
+		{1, 5000 + FrameSize, 25 * time.Millisecond},
+		{1, 5000 + 3*FrameSize, 100 * time.Millisecond},
+		{2, 77, 1200 * time.Millisecond},
+	}
+	for _, s := range steps {
+		if err := track.Write(s.ssrc, s.timestamp, s.at, stereo(100)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := track.Pad(1500 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := track.Duration(); got != 1500*time.Millisecond {
+		t.Errorf("track is %v long, want 1.5s", got)
+	}
+
+	samples := readSamples(t, track.Path)
+	for _, want := range []struct {
+		at    time.Duration
+		level int16
+	}{
+		{10 * time.Millisecond, 0},
+		{30 * time.Millisecond, 100},
+		{50 * time.Millisecond, 100},
+		{70 * time.Millisecond, 0}, // The RTP clock skipped a frame.
+		{90 * time.Millisecond, 100},
+		{500 * time.Millisecond, 0},
+		{1210 * time.Millisecond, 100},
+		{1400 * time.Millisecond, 0},
+	} {
+		if got := samples[samplesIn(want.at)]; got != want.level {
+			t.Errorf("sample at %v is %d, want %d", want.at, got, want.level)
+		}
+	}
+}
+
+func TestMixClips(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, samples ...int16) string {
+		path := filepath.Join(dir, name)
+		data := make([]byte, 2*len(samples))
+		for i, s := range samples {
+			binary.LittleEndian.PutUint16(data[2*i:], uint16(s))
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	a := write("a.pcm", 100, 30000, -30000)
+	b := write("b.pcm", 5, 10000, -10000, 7)
+
+	out := filepath.Join(dir, "mix.pcm")
+	if err := Mix(out, a, b); err != nil {
+		t.Fatal(err)
+	}
+	got := readSamples(t, out)
+	want := []int16{105, 32767, -32768, 7}
+	if len(got) != len(want) {
+		t.Fatalf("mixed %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("mixed %v, want %v", got, want)
+		}
+	}
+}
+
+func TestWriteWAV(t *testing.T) {
+	pcm := []byte{1, 0, 2, 0, 3, 0}
+	var buf bytes.Buffer
+	if err := WriteWAV(&buf, bytes.NewReader(pcm), int64(len(pcm))); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if len(data) != wavHeaderSize+len(pcm) {
+		t.Fatalf("wrote %d bytes, want %d", len(data), wavHeaderSize+len(pcm))
+	}
+	if string(data[:4]) != "RIFF" || string(data[8:16]) != "WAVEfmt " || string(data[36:40]) != "data" {
+		t.Errorf("bad header %q", data[:wavHeaderSize])
+	}
+	if got := binary.LittleEndian.Uint32(data[24:]); got != SampleRate {
+		t.Errorf("sample rate %d, want %d", got, SampleRate)
+	}
+	if got := binary.LittleEndian.Uint32(data[40:]); got != uint32(len(pcm)) {
+		t.Errorf("data size %d, want %d", got, len(pcm))
+	}
+	if !bytes.Equal(data[wavHeaderSize:], pcm) {
+		t.Error("samples weren't copied")
+	}
+}
+
+func TestWriteOgg(t *testing.T) {
+	// A little over two pages of audio, ending part way through a frame.
+	samples := (2*pagePackets+3)*FrameSize + 100
+	var buf bytes.Buffer
+	if err := WriteOgg(&buf, bytes.NewReader(make([]byte, 2*samples))); err != nil {
+		t.Fatal(err)
+	}
+
+	type page struct {
+		flags   byte
+		granule uint64
+		packets int
+		body    []byte
+	}
+	var pages []page
+	data := buf.Bytes()
+	for len(data) > 0 {
+		if len(data) < 27 || string(data[:4]) != "OggS" {
+			t.Fatalf("page %d has no capture pattern", len(pages))
+		}
+		lacing := data[27 : 27+int(data[26])]
+		size, packets := 0, 0
+		for _, n := range lacing {
+			size += int(n)
+			if n < 255 {
+				packets++
+			}
+		}
+		raw := data[:27+len(lacing)+size]
+		checksum := binary.LittleEndian.Uint32(raw[22:])
+		check := append([]byte(nil), raw...)
+		binary.LittleEndian.PutUint32(check[22:], 0)
+		if oggChecksum(check) != checksum {
+			t.Errorf("page %d has a bad checksum", len(pages))
+		}
+		if seq := binary.LittleEndian.Uint32(raw[18:]); seq != uint32(len(pages)) {
+			t.Errorf("page %d has sequence number %d", len(pages), seq)
+		}
+		pages = append(pages, page{
+			flags:   raw[5],
+			granule: binary.LittleEndian.Uint64(raw[6:]),
+			packets: packets,
+			body:    raw[27+len(lacing):],
+		})
+		data = data[len(raw):]
+	}
+
+	if len(pages) != 5 {
+		t.Fatalf("wrote %d pages, want headers and 3 audio pages", len(pages))
+	}
+	if pages[0].flags != pageFirst || !bytes.HasPrefix(pages[0].body, []byte("OpusHead")) {
+		t.Error("first page isn't the Opus header")
+	}
+	if !bytes.HasPrefix(pages[1].body, []byte("OpusTags")) {
+		t.Error("second page isn't the Opus tags")
+	}
+	if pages[2].granule != preSkip+pagePackets*FrameSize || pages[2].packets != pagePackets {
+		t.Errorf("first audio page has %d packets ending at %d", pages[2].packets, pages[2].granule)
+	}
+	last := pages[4]
+	if last.flags != pageLast || last.granule != uint64(preSkip+samples) || last.packets != 4 {
+		t.Errorf("last page has flags %#x and %d packets ending at %d, want %#x, 4 and %d",
+			last.flags, last.packets, last.granule, pageLast, preSkip+samples)
+	}
+}
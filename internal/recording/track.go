@@ -0,0 +1,123 @@
+// Package recording saves voice channel audio: one track per speaker, kept in
+// step with the others so they line up when played together, exported as WAV
+// or Ogg Opus files and optionally mixed down into one.
+package recording
+
+import (
+	"bufio"
+	"encoding/binary"
+	"os"
+	"time"
+)
+
+// Tracks are 16-bit mono PCM at Discord's sample rate. Speakers are one voice
+// each, so stereo would only double the size.
+const (
+	SampleRate = 48000
+	FrameSize  = 960 // Samples in 20ms.
+)
+
+// resyncAfter is how far a speaker's RTP clock may drift from the wall clock
+// before the track follows the wall clock again.
+const resyncAfter = time.Second
+
+// Track is one speaker's audio, written to disk as raw PCM so long sessions
+// don't sit in memory. Silence fills the time the speaker was quiet, so
+// every track of a recording starts at the same moment.
+type Track struct {
+	Path    string
+	file    *os.File
+	out     *bufio.Writer
+	samples int64             // Written so far.
+	anchors map[uint32]anchor // SSRC -> where its RTP clock falls in the track.
+}
+
+// anchor ties an RTP timestamp to a sample position in the track.
+type anchor struct {
+	timestamp uint32
+	sample    int64
+}
+
+// NewTrack creates an empty track at path.
+func NewTrack(path string) (*Track, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Track{
+		Path:    path,
+		file:    file,
+		out:     bufio.NewWriterSize(file, 64<<10),
+		anchors: make(map[uint32]anchor),
+	}, nil
+}
+
+// Write adds a frame of interleaved stereo audio. The frame is placed by its
+// RTP timestamp relative to the stream's first frame, which at arrived after
+// the recording started. Frames that would overlap earlier audio are appended
+// instead, as the track only grows.
+func (t *Track) Write(ssrc, timestamp uint32, at time.Duration, stereo []int16) error {
+	wall := samplesIn(at)
+	pos := wall
+	if a, ok := t.anchors[ssrc]; ok {
+		pos = a.sample + int64(int32(timestamp-a.timestamp))
+	}
+	if d := pos - wall; d > samplesIn(resyncAfter) || d < -samplesIn(resyncAfter) {
+		pos = wall
+	}
+	if pos < t.samples {
+		pos = t.samples
+	}
+	t.anchors[ssrc] = anchor{timestamp: timestamp, sample: pos}
+
+	if err := t.padTo(pos); err != nil {
+		return err
+	}
+	var sample [2]byte
+	for i := 0; i+1 < len(stereo); i += 2 {
+		binary.LittleEndian.PutUint16(sample[:], uint16((int32(stereo[i])+int32(stereo[i+1]))/2))
+		if _, err := t.out.Write(sample[:]); err != nil {
+			return err
+		}
+		t.samples++
+	}
+	return nil
+}
+
+// Pad writes silence until the track is d long, so tracks of speakers who
+// stopped talking early end with the recording.
+func (t *Track) Pad(d time.Duration) error {
+	return t.padTo(samplesIn(d))
+}
+
+// padTo writes silence until the track is samples long.
+func (t *Track) padTo(samples int64) error {
+	var silence [2 * FrameSize]byte
+	for t.samples < samples {
+		n := min(samples-t.samples, FrameSize)
+		if _, err := t.out.Write(silence[:2*n]); err != nil {
+			return err
+		}
+		t.samples += n
+	}
+	return nil
+}
+
+// Duration returns how much audio the track holds.
+func (t *Track) Duration() time.Duration {
+	return time.Duration(t.samples) * time.Second / SampleRate
+}
+
+// Close finishes writing the track. The raw PCM stays at Path for Export.
+func (t *Track) Close() error {
+	err := t.out.Flush()
+	if cerr := t.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// samplesIn converts a duration to a number of samples.
+func samplesIn(d time.Duration) int64 {
+	return int64(d) * SampleRate / int64(time.Second)
+}
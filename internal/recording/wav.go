@@ -0,0 +1,40 @@
+package recording
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// wavHeaderSize is the size of the RIFF header in front of the samples.
+const wavHeaderSize = 44
+
+// WriteWAV writes size bytes of raw mono PCM from pcm to w as a WAV file.
+func WriteWAV(w io.Writer, pcm io.Reader, size int64) error {
+	if size > 1<<32-1-wavHeaderSize {
+		return errors.New("recording is too long for a WAV file")
+	}
+
+	header := make([]byte, 0, wavHeaderSize)
+	header = append(header, "RIFF"...)
+	header = binary.LittleEndian.AppendUint32(header, uint32(wavHeaderSize-8+size))
+	header = append(header, "WAVEfmt "...)
+	header = binary.LittleEndian.AppendUint32(header, 16)           // Format chunk size.
+	header = binary.LittleEndian.AppendUint16(header, 1)            // PCM.
+	header = binary.LittleEndian.AppendUint16(header, 1)            // Channels.
+	header = binary.LittleEndian.AppendUint32(header, SampleRate)   // Sample rate.
+	header = binary.LittleEndian.AppendUint32(header, SampleRate*2) // Bytes per second.
+	header = binary.LittleEndian.AppendUint16(header, 2)            // Bytes per sample.
+	header = binary.LittleEndian.AppendUint16(header, 16)           // Bits per sample.
+	header = append(header, "data"...)
+	header = binary.LittleEndian.AppendUint32(header, uint32(size))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	n, err := io.Copy(w, io.LimitReader(pcm, size))
+	if err == nil && n < size {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+package speech
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"strings"
+)
+
+// Command runs a local, offline speech engine such as a whisper.cpp or Vosk
+// wrapper, once per stream. The program reads SampleRate mono 16-bit
+// little-endian PCM on stdin and prints one final transcript per line.
+type Command struct {
+	Path string
+	Args []string
+}
+
+func (c Command) Stream(ctx context.Context) (Stream, error) {
+	cmd := exec.CommandContext(ctx, c.Path, c.Args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting %s: %w", c.Path, err)
+	}
+
+	s := &commandStream{cmd: cmd, stdin: stdin, stderr: &stderr, results: make(chan Transcript, 16)}
+	go s.receive(stdout)
+	return s, nil
+}
+
+type commandStream struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stderr  *strings.Builder
+	results chan Transcript
+}
+
+func (s *commandStream) Write(pcm []int16) error {
+	_, err := s.stdin.Write(pcmBytes(pcm))
+	return err
+}
+
+func (s *commandStream) Results() <-chan Transcript { return s.results }
+
+// Close ends the engine's input. It keeps running until it has printed what's
+// left.
+func (s *commandStream) Close() error { return s.stdin.Close() }
+
+// receive passes the engine's lines on and reaps it once it exits.
+func (s *commandStream) receive(stdout io.Reader) {
+	defer close(s.results)
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		if text := strings.TrimSpace(scanner.Text()); text != "" {
+			s.results <- Transcript{Text: text, Final: true}
+		}
+	}
+	if err := s.cmd.Wait(); err != nil {
+		log.Printf("Speech engine %s exited: %v %s", s.cmd.Path, err, strings.TrimSpace(s.stderr.String()))
+	}
+}
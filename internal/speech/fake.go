@@ -0,0 +1,47 @@
+package speech
+
+import (
+	"context"
+	"time"
+)
+
+// Fake "recognizes" Text in every Every of audio it's given, standing in for a
+// real engine when testing.
+type Fake struct {
+	Text  string
+	Every time.Duration // Defaults to a second.
+}
+
+func (f Fake) Stream(ctx context.Context) (Stream, error) {
+	every := f.Every
+	if every <= 0 {
+		every = time.Second
+	}
+	return &fakeStream{
+		text:    f.Text,
+		every:   int(every.Seconds() * SampleRate),
+		results: make(chan Transcript, 16),
+	}, nil
+}
+
+type fakeStream struct {
+	text    string
+	every   int // Samples per transcript.
+	heard   int
+	results chan Transcript
+}
+
+func (s *fakeStream) Write(pcm []int16) error {
+	s.heard += len(pcm)
+	for ; s.heard >= s.every; s.heard -= s.every {
+		s.results <- Transcript{Text: s.text, Final: true}
+	}
+	return nil
+}
+
+func (s *fakeStream) Results() <-chan Transcript { return s.results }
+
+func (s *fakeStream) Close() error {
+	close(s.results)
+	return nil
+}
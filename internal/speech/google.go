@@ -0,0 +1,97 @@
+package speech
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	speech "cloud.google.com/go/speech/apiv1"
+	"cloud.google.com/go/speech/apiv1/speechpb"
+)
+
+// Google recognizes speech with Google Cloud Speech-to-Text. Credentials are
+// found the usual way, e.g. from GOOGLE_APPLICATION_CREDENTIALS.
+type Google struct {
+	Client   *speech.Client
+	Language string // BCP-47 language code, e.g. en-US.
+}
+
+// NewGoogle connects to Google Cloud Speech-to-Text.
+func NewGoogle(ctx context.Context, language string) (*Google, error) {
+	client, err := speech.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("creating speech client: %w", err)
+	}
+	return &Google{Client: client, Language: language}, nil
+}
+
+// Close closes the connection to Google.
+func (g *Google) Close() error {
+	return g.Client.Close()
+}
+
+// Stream opens a streaming recognition request. Google ends streams after
+// about five minutes, so callers should keep them to one stretch of speech.
+func (g *Google) Stream(ctx context.Context) (Stream, error) {
+	client, err := g.Client.StreamingRecognize(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("opening recognition stream: %w", err)
+	}
+	err = client.Send(&speechpb.StreamingRecognizeRequest{
+		StreamingRequest: &speechpb.StreamingRecognizeRequest_StreamingConfig{
+			StreamingConfig: &speechpb.StreamingRecognitionConfig{
+				Config: &speechpb.RecognitionConfig{
+					Encoding:                   speechpb.RecognitionConfig_LINEAR16,
+					SampleRateHertz:            SampleRate,
+					LanguageCode:               g.Language,
+					EnableAutomaticPunctuation: true,
+				},
+				InterimResults: true,
+			},
+		},
+	})
+	if err != nil {
+		client.CloseSend()
+		return nil, fmt.Errorf("sending recognition config: %w", err)
+	}
+
+	s := &googleStream{client: client, results: make(chan Transcript, 16)}
+	go s.receive()
+	return s, nil
+}
+
+type googleStream struct {
+	client  speechpb.Speech_StreamingRecognizeClient
+	results chan Transcript
+}
+
+func (s *googleStream) Write(pcm []int16) error {
+	return s.client.Send(&speechpb.StreamingRecognizeRequest{
+		StreamingRequest: &speechpb.StreamingRecognizeRequest_AudioContent{AudioContent: pcmBytes(pcm)},
+	})
+}
+
+func (s *googleStream) Results() <-chan Transcript { return s.results }
+
+func (s *googleStream) Close() error { return s.client.CloseSend() }
+
+// receive passes Google's results on until the stream ends.
+func (s *googleStream) receive() {
+	defer close(s.results)
+	for {
+		resp, err := s.client.Recv()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, context.Canceled) {
+				log.Printf("Error receiving speech recognition: %v", err)
+			}
+			return
+		}
+		for _, result := range resp.GetResults() {
+			if alts := result.GetAlternatives(); len(alts) > 0 {
+				s.results <- Transcript{Text: alts[0].GetTranscript(), Final: result.GetIsFinal()}
+			}
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package speech
+
+// Discord delivers voice as 48 kHz interleaved stereo, three times the rate
+// recognizers take.
+const (
+	discordRate     = 48000
+	discordChannels = 2
+	ratio           = discordRate / SampleRate
+)
+
+// Downsample converts 48 kHz interleaved stereo PCM from Discord to SampleRate
+// mono. Each output sample averages both channels over three input frames,
+// which also filters out most of what would alias at the lower rate.
+func Downsample(pcm []int16) []int16 {
+	const block = ratio * discordChannels
+	out := make([]int16, len(pcm)/block)
+	for i := range out {
+		sum := 0
+		for _, sample := range pcm[i*block : (i+1)*block] {
+			sum += int(sample)
+		}
+		out[i] = int16(sum / block)
+	}
+	return out
+}
@@ -0,0 +1,40 @@
+// Package speech turns voice audio into text. Recognizers are pluggable: Google
+// Cloud Speech, an offline engine run as a local program, or a fake for tests.
+package speech
+
+import (
+	"context"
+	"encoding/binary"
+)
+
+// SampleRate is the rate, in Hz, of the mono 16-bit PCM recognizers take.
+const SampleRate = 16000
+
+// Transcript is a piece of recognized speech.
+type Transcript struct {
+	Text  string
+	Final bool // False for interim guesses that a later transcript replaces.
+}
+
+// Recognizer opens recognition streams, one per speaker.
+type Recognizer interface {
+	Stream(ctx context.Context) (Stream, error)
+}
+
+// Stream recognizes one speaker's audio. Close ends the audio, after which the
+// remaining transcripts are delivered and Results is closed.
+type Stream interface {
+	// Write sends SampleRate mono PCM to the recognizer.
+	Write(pcm []int16) error
+	Results() <-chan Transcript
+	Close() error
+}
+
+// pcmBytes encodes samples as 16-bit little-endian PCM.
+func pcmBytes(pcm []int16) []byte {
+	buf := make([]byte, 2*len(pcm))
+	for i, sample := range pcm {
+		binary.LittleEndian.PutUint16(buf[2*i:], uint16(sample))
+	}
+	return buf
+}
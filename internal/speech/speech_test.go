@@ -0,0 +1,79 @@
+package speech
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDownsample(t *testing.T) {
+	// Three stereo frames become one mono sample averaging all six values.
+	pcm := []int16{
+		100, 200, 300, 400, 500, 600,
+		-600, -600, -600, -600, -600, -600,
+		7, // A partial frame at the end is dropped.
+	}
+	got := Downsample(pcm)
+	if want := []int16{350, -600}; !slices.Equal(got, want) {
+		t.Errorf("Downsample = %v, want %v", got, want)
+	}
+
+	// 20ms of Discord audio is 960 stereo frames, or 320 samples at 16 kHz.
+	if got := len(Downsample(make([]int16, 960*2))); got != 320 {
+		t.Errorf("20ms downsampled to %d samples, want 320", got)
+	}
+}
+
+func TestPCMBytes(t *testing.T) {
+	got := pcmBytes([]int16{1, -2, 0x1234})
+	want := []byte{0x01, 0x00, 0xfe, 0xff, 0x34, 0x12}
+	if !slices.Equal(got, want) {
+		t.Errorf("pcmBytes = %x, want %x", got, want)
+	}
+}
+
+func TestFake(t *testing.T) {
+	stream, err := Fake{Text: "hello", Every: 500 * time.Millisecond}.Stream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 1.2 seconds of audio in 20ms chunks.
+	for range 60 {
+		if err := stream.Write(make([]int16, SampleRate/50)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stream.Close()
+
+	var got []Transcript
+	for tr := range stream.Results() {
+		got = append(got, tr)
+	}
+	if len(got) != 2 || got[0] != (Transcript{Text: "hello", Final: true}) {
+		t.Errorf("got transcripts %v, want two finals", got)
+	}
+}
+
+func TestWakeCommand(t *testing.T) {
+	tests := []struct {
+		transcript, phrase string
+		want               string
+		ok                 bool
+	}{
+		{"hey bot skip", "hey bot", "skip", true},
+		{"Hey, Bot! Play Never Gonna Give You Up.", "hey bot", "play never gonna give you up", true},
+		{"hey bot seek 1:30", "hey bot", "seek 1:30", true},
+		{"okay jarvis volume 50%", "Okay Jarvis", "volume 50", true},
+		{"hey bot", "hey bot", "", false},
+		{"hey bob skip", "hey bot", "", false},
+		{"so I said hey bot skip", "hey bot", "", false},
+		{"skip", "", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := WakeCommand(tt.transcript, tt.phrase)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("WakeCommand(%q, %q) = %q, %v, want %q, %v", tt.transcript, tt.phrase, got, ok, tt.want, tt.ok)
+		}
+	}
+}
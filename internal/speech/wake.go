@@ -0,0 +1,38 @@
+package speech
+
+import (
+	"strings"
+	"unicode"
+)
+
+// WakeCommand reports whether transcript starts with the wake phrase and
+// returns the rest of it, lowercased and without the punctuation recognizers
+// put around words. Nothing after the phrase doesn't count as a command.
+func WakeCommand(transcript, phrase string) (string, bool) {
+	words := normalize(transcript)
+	wake := normalize(phrase)
+	if len(wake) == 0 || len(words) <= len(wake) {
+		return "", false
+	}
+	for i, word := range wake {
+		if words[i] != word {
+			return "", false
+		}
+	}
+	return strings.Join(words[len(wake):], " "), true
+}
+
+// normalize splits text into lowercase words, trimming punctuation from their
+// ends so "Bot," matches "bot" but "1:30" stays whole.
+func normalize(text string) []string {
+	var words []string
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		word = strings.TrimFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+)
+
+const schemaVersionKey = "schema_version"
+
+// migrations upgrade the store one schema version at a time. Append new
+// migrations to the end; never reorder or edit ones that have shipped.
+var migrations = []func(s Store) error{
+	// 1: Move the hard-coded LeBron stats DM out of StartCydCron.
+	func(s Store) error {
+		jobs := NewRepository[ScheduledDM](s, BucketJobs)
+		return jobs.Put("cyd-lebron-stats", ScheduledDM{
+			GuildName: "King's Landing",
+			Username:  "cydstynine",
+			Schedule:  "@daily",
+			Team:      "Lakers",
+			Player:    "LeBron James",
+			Season:    "2024",
+		})
+	},
+}
+
+// SchemaVersion returns the number of migrations applied to the store.
+func SchemaVersion(s Store) (int, error) {
+	data, err := s.Get(BucketMeta, schemaVersionKey)
+	if err != nil || data == nil {
+		return 0, err
+	}
+	return strconv.Atoi(string(data))
+}
+
+// Migrate applies every migration the store hasn't seen yet.
+func Migrate(s Store) error {
+	version, err := SchemaVersion(s)
+	if err != nil {
+		return fmt.Errorf("reading schema version: %w", err)
+	}
+
+	for i := version; i < len(migrations); i++ {
+		log.Printf("Applying storage migration %d", i+1)
+		if err := migrations[i](s); err != nil {
+			return fmt.Errorf("migration %d: %w", i+1, err)
+		}
+		if err := s.Put(BucketMeta, schemaVersionKey, []byte(strconv.Itoa(i+1))); err != nil {
+			return fmt.Errorf("recording schema version %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
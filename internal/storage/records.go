@@ -0,0 +1,81 @@
+package storage
+
+import "time"
+
+// QueueState is a guild's music queue, saved whenever it changes so playback can
+// resume after a restart. Keyed by guild ID.
+type QueueState struct {
+	TextChannelID  string       `json:"text_channel_id"`
+	VoiceChannelID string       `json:"voice_channel_id"`
+	Songs          []QueuedSong `json:"songs"`
+	URLs           []string     `json:"urls,omitempty"`     // Queues saved before songs had details.
+	Position       float64      `json:"position,omitempty"` // Seconds into the first song playback got.
+}
+
+// QueuedSong is a song in a saved queue.
+type QueuedSong struct {
+	URL         string  `json:"url"`
+	File        string  `json:"file,omitempty"`
+	Title       string  `json:"title,omitempty"`
+	Uploader    string  `json:"uploader,omitempty"`
+	Seconds     float64 `json:"seconds,omitempty"`
+	Thumbnail   string  `json:"thumbnail,omitempty"`
+	RequestedBy string  `json:"requested_by,omitempty"`
+}
+
+// ScheduledDM is a cron job that DMs a player's season averages to a member.
+// Keyed by job ID.
+type ScheduledDM struct {
+	GuildName string `json:"guild_name"`
+	Username  string `json:"username"`
+	UserID    string `json:"user_id,omitempty"` // Resolved from Username on first run.
+	Schedule  string `json:"schedule"`
+	Team      string `json:"team"`
+	Player    string `json:"player"`
+	Season    string `json:"season"`
+}
+
+// GuildSettings holds per-guild configuration. Unset fields fall back to the
+// bot's defaults. Keyed by guild ID.
+type GuildSettings struct {
+	Prefix              string   `json:"prefix,omitempty"`
+	TimeoutMinutes      int      `json:"timeout_minutes,omitempty"`
+	NewsCountry         string   `json:"news_country,omitempty"`
+	Volume              *int     `json:"volume,omitempty"` // Percent of normal loudness.
+	AnnounceChannelID   string   `json:"announce_channel_id,omitempty"`
+	SearchMode          string   `json:"search_mode,omitempty"` // "pick" or "top".
+	PlaylistMax         int      `json:"playlist_max,omitempty"`
+	MaxSongSeconds      int      `json:"max_song_seconds,omitempty"`      // Zero for no limit.
+	Filters             []string `json:"filters,omitempty"`               // Audio effects that are on, e.g. "bassboost".
+	Speed               float64  `json:"speed,omitempty"`                 // Playback tempo, zero for normal.
+	AloneTimeoutSeconds int      `json:"alone_timeout_seconds,omitempty"` // Grace period before leaving an empty channel.
+	WakePhrase          string   `json:"wake_phrase,omitempty"`           // Starts a voice command, e.g. "hey bot".
+}
+
+// SavedPlaylist is a named list of songs kept for replaying later. Keyed by
+// "user/<user ID>/<name>" for personal playlists and "guild/<guild ID>/<name>"
+// for a server's shared ones.
+type SavedPlaylist struct {
+	Name    string       `json:"name"`
+	OwnerID string       `json:"owner_id"`
+	Songs   []QueuedSong `json:"songs"`
+	Updated time.Time    `json:"updated"`
+}
+
+// RecordingConsent is a member's agreement to be recorded in a guild's voice
+// channels. Keyed by "<guild ID>/<user ID>"; members without one aren't recorded.
+type RecordingConsent struct {
+	Given time.Time `json:"given"`
+}
+
+// ConversationMessage is one turn of an AI conversation.
+type ConversationMessage struct {
+	Role    string    `json:"role"`
+	Content string    `json:"content"`
+	Time    time.Time `json:"time"`
+}
+
+// Conversation is the recent AI history for a channel. Keyed by channel ID.
+type Conversation struct {
+	Messages []ConversationMessage `json:"messages"`
+}
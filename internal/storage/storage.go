@@ -0,0 +1,90 @@
+// Package storage persists bot state (music queues, scheduled jobs, guild
+// settings, AI conversations, saved playlists, recording consent) so the bot
+// can pick up where it left off after a restart.
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Buckets group related records in the store.
+const (
+	BucketMeta          = "meta"
+	BucketQueues        = "queues"
+	BucketJobs          = "jobs"
+	BucketGuildSettings = "guild_settings"
+	BucketPermissions   = "permissions"
+	BucketAudit         = "audit"
+	BucketConversations = "conversations"
+	BucketPlaylists     = "playlists"
+	BucketConsent       = "recording_consent"
+)
+
+// ErrNotFound is returned by Repository.Get when a key has no record.
+var ErrNotFound = errors.New("record not found")
+
+// Store is a bucketed key/value store. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	Get(bucket, key string) ([]byte, error)
+	Put(bucket, key string, value []byte) error
+	Delete(bucket, key string) error
+	ForEach(bucket string, fn func(key string, value []byte) error) error
+	Close() error
+}
+
+// Repository stores JSON encoded records of a single type in one bucket.
+type Repository[T any] struct {
+	store  Store
+	bucket string
+}
+
+func NewRepository[T any](store Store, bucket string) *Repository[T] {
+	return &Repository[T]{store: store, bucket: bucket}
+}
+
+// Get loads the record for key, returning ErrNotFound when there is none.
+func (r *Repository[T]) Get(key string) (T, error) {
+	var record T
+	data, err := r.store.Get(r.bucket, key)
+	if err != nil {
+		return record, err
+	}
+	if data == nil {
+		return record, ErrNotFound
+	}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return record, fmt.Errorf("decoding %s/%s: %w", r.bucket, key, err)
+	}
+	return record, nil
+}
+
+// Put saves the record for key, replacing any existing one.
+func (r *Repository[T]) Put(key string, record T) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("encoding %s/%s: %w", r.bucket, key, err)
+	}
+	return r.store.Put(r.bucket, key, data)
+}
+
+// Delete removes the record for key. Deleting a missing key is not an error.
+func (r *Repository[T]) Delete(key string) error {
+	return r.store.Delete(r.bucket, key)
+}
+
+// All loads every record in the bucket keyed by its key.
+func (r *Repository[T]) All() (map[string]T, error) {
+	records := make(map[string]T)
+	err := r.store.ForEach(r.bucket, func(key string, value []byte) error {
+		var record T
+		if err := json.Unmarshal(value, &record); err != nil {
+			return fmt.Errorf("decoding %s/%s: %w", r.bucket, key, err)
+		}
+		records[key] = record
+		return nil
+	})
+	return records, err
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func openTestStore(t *testing.T) *BoltStore {
+	t.Helper()
+	store, err := Open(filepath.Join(t.TempDir(), "bot.db"))
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestRepository(t *testing.T) {
+	queues := NewRepository[QueueState](openTestStore(t), BucketQueues)
+
+	if _, err := queues.Get("guild"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get on empty bucket = %v, want ErrNotFound", err)
+	}
+
+	want := QueueState{TextChannelID: "text", VoiceChannelID: "voice", URLs: []string{"a", "b"}}
+	if err := queues.Put("guild", want); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+
+	got, err := queues.Get("guild")
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Get = %+v, want %+v", got, want)
+	}
+
+	all, err := queues.All()
+	if err != nil || len(all) != 1 {
+		t.Errorf("All = %v, %v; want one record", all, err)
+	}
+
+	if err := queues.Delete("guild"); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if _, err := queues.Get("guild"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMigrationsRunOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bot.db")
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	version, err := SchemaVersion(store)
+	if err != nil || version != len(migrations) {
+		t.Fatalf("SchemaVersion = %d, %v; want %d", version, err, len(migrations))
+	}
+
+	// A job removed by the user must not be re-seeded on the next start.
+	jobs := NewRepository[ScheduledDM](store, BucketJobs)
+	if err := jobs.Delete("cyd-lebron-stats"); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	store.Close()
+
+	store, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen returned error: %v", err)
+	}
+	defer store.Close()
+
+	all, err := NewRepository[ScheduledDM](store, BucketJobs).All()
+	if err != nil {
+		t.Fatalf("All returned error: %v", err)
+	}
+	if len(all) != 0 {
+		t.Errorf("jobs after reopen = %v, want none", all)
+	}
+}